@@ -0,0 +1,225 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package admission
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// Priority orders the requests waiting for admission.
+type Priority int
+
+const (
+	// PriorityBulk is used for background jobs that can wait.
+	PriorityBulk Priority = iota
+	// PriorityInteractive is used for requests a user is waiting on.
+	PriorityInteractive
+)
+
+// ParsePriority parses the name of a priority class.
+func ParsePriority(name string) (Priority, error) {
+	switch name {
+	case "bulk":
+		return PriorityBulk, nil
+	case "interactive":
+		return PriorityInteractive, nil
+	default:
+		return PriorityBulk, util.NewBadRequestError("unknown priority '%s'", name)
+	}
+}
+
+// String returns the name of the priority class.
+func (p Priority) String() string {
+	if p == PriorityInteractive {
+		return "interactive"
+	}
+	return "bulk"
+}
+
+type contextKey struct{}
+
+// NewContext returns a context holding the priority the request was
+// admitted with.
+func NewContext(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, contextKey{}, priority)
+}
+
+// FromContext returns the priority the request was admitted with, defaulting
+// to interactive for requests that did not go through admission.
+func FromContext(ctx context.Context) Priority {
+	priority, ok := ctx.Value(contextKey{}).(Priority)
+	if !ok {
+		return PriorityInteractive
+	}
+	return priority
+}
+
+type waiter struct {
+	priority Priority
+	sequence uint64
+	ready    chan struct{}
+	index    int
+}
+
+// waitQueue orders waiters by priority, then by arrival.
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].sequence < q[j].sequence
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return w
+}
+
+// Controller bounds the number of requests served concurrently. Requests
+// over the bound wait in a bounded queue, highest priority first.
+type Controller struct {
+	maxActive int
+	queueSize int
+	timeout   time.Duration
+
+	mutex    sync.Mutex
+	active   int
+	sequence uint64
+	waiting  waitQueue
+}
+
+// NewController creates a controller serving at most maxActive requests at
+// once, with up to queueSize requests waiting at most timeout for a slot. A
+// nil controller, which admits everything, is returned if maxActive is not
+// positive.
+func NewController(maxActive int, queueSize int, timeout time.Duration) *Controller {
+	if maxActive <= 0 {
+		return nil
+	}
+	return &Controller{
+		maxActive: maxActive,
+		queueSize: queueSize,
+		timeout:   timeout,
+	}
+}
+
+// Acquire waits for a slot, returning the function releasing it. An
+// unavailable error is returned if the queue is full or the wait times out.
+func (c *Controller) Acquire(ctx context.Context, priority Priority) (func(), error) {
+	if c == nil {
+		return func() {}, nil
+	}
+
+	c.mutex.Lock()
+	if c.active < c.maxActive && len(c.waiting) == 0 {
+		c.active++
+		c.mutex.Unlock()
+		metrics.AddAdmissionActive(1)
+		return c.releaser(), nil
+	}
+	if len(c.waiting) >= c.queueSize {
+		c.mutex.Unlock()
+		metrics.AddAdmissionRejected("queue_full")
+		return nil, util.WithKind(errors.Errorf("admission queue is full"), util.KindUnavailable)
+	}
+
+	c.sequence++
+	w := &waiter{
+		priority: priority,
+		sequence: c.sequence,
+		ready:    make(chan struct{}),
+	}
+	heap.Push(&c.waiting, w)
+	c.mutex.Unlock()
+
+	metrics.AddAdmissionWaiting(priority.String(), 1)
+	defer metrics.AddAdmissionWaiting(priority.String(), -1)
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return c.releaser(), nil
+	case <-timer.C:
+		metrics.AddAdmissionRejected("timeout")
+		err = util.WithKind(errors.Errorf("timed out waiting for admission"), util.KindUnavailable)
+	case <-ctx.Done():
+		metrics.AddAdmissionRejected("cancelled")
+		err = errors.Wrap(ctx.Err(), "request cancelled while waiting for admission")
+	}
+
+	c.mutex.Lock()
+	select {
+	case <-w.ready:
+		// the slot was handed over while giving up so pass it on
+		c.mutex.Unlock()
+		c.release()
+	default:
+		heap.Remove(&c.waiting, w.index)
+		c.mutex.Unlock()
+	}
+
+	return nil, err
+}
+
+func (c *Controller) releaser() func() {
+	once := sync.Once{}
+	return func() {
+		once.Do(c.release)
+	}
+}
+
+// release hands the slot to the next waiter, if any.
+func (c *Controller) release() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.waiting) > 0 {
+		w := heap.Pop(&c.waiting).(*waiter)
+		close(w.ready)
+		return
+	}
+	c.active--
+	metrics.AddAdmissionActive(-1)
+}
@@ -0,0 +1,99 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// Limits applies the rate limits per client and per pipeline shared by every
+// transport. The limiters can be replaced while requests are being served.
+type Limits struct {
+	mutex    sync.RWMutex
+	client   *RateLimiter
+	pipeline *RateLimiter
+}
+
+// NewLimits creates the limits using the client and pipeline limiters, either
+// of which can be nil to disable it.
+func NewLimits(client *RateLimiter, pipeline *RateLimiter) *Limits {
+	return &Limits{
+		client:   client,
+		pipeline: pipeline,
+	}
+}
+
+// Set replaces the limiters.
+func (l *Limits) Set(client *RateLimiter, pipeline *RateLimiter) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.client = client
+	l.pipeline = pipeline
+}
+
+// Allow consumes a token for the client and, if not empty, the pipeline. A
+// rate limited error is returned along with the time until a token is
+// available if either has none left.
+func (l *Limits) Allow(clientKey string, pipelineID string) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	l.mutex.RLock()
+	client, pipeline := l.client, l.pipeline
+	l.mutex.RUnlock()
+
+	allowed, wait := client.Allow(clientKey)
+	if !allowed {
+		return wait, rateLimited(wait, "client")
+	}
+	if pipelineID != "" {
+		allowed, wait = pipeline.Allow(pipelineID)
+		if !allowed {
+			return wait, rateLimited(wait, fmt.Sprintf("pipeline '%s'", pipelineID))
+		}
+	}
+
+	return 0, nil
+}
+
+// ClientKey identifies the caller, using the authenticated principal if any
+// and the remote address otherwise.
+func ClientKey(ctx context.Context, address string) string {
+	if principal := auth.FromContext(ctx); principal != nil {
+		return "principal:" + principal.Name
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	return "address:" + host
+}
+
+func rateLimited(wait time.Duration, limited string) error {
+	metrics.AddAdmissionRejected("rate_limited")
+	return util.WithKind(errors.Errorf("rate limit exceeded for %s, retry in %s", limited, wait.Round(time.Second)), util.KindRateLimited)
+}
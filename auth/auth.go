@@ -0,0 +1,191 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// Scope is a permission granted to a principal.
+type Scope string
+
+const (
+	// ScopeRead allows listing pipelines, runs and configuration.
+	ScopeRead Scope = "read"
+	// ScopeProduce allows producing and scoring predictions.
+	ScopeProduce Scope = "produce"
+	// ScopeFit allows fitting pipelines.
+	ScopeFit Scope = "fit"
+	// ScopeAdmin allows everything, including uploading pipelines.
+	ScopeAdmin Scope = "admin"
+
+	// APIKeyHeader is the header holding the API key of a request.
+	APIKeyHeader = "X-API-Key"
+
+	// AllPipelines grants access to every pipeline.
+	AllPipelines = "*"
+)
+
+type contextKey struct{}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Name      string   `json:"name"`
+	Scopes    []Scope  `json:"scopes"`
+	Pipelines []string `json:"pipelines"`
+}
+
+// HasScope returns true if the principal was granted the scope. The admin
+// scope grants every other scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccess returns true if the pipeline matches one of the patterns the
+// principal was granted. Admins can access every pipeline.
+func (p *Principal) CanAccess(pipelineID string) bool {
+	if p.HasScope(ScopeAdmin) {
+		return true
+	}
+	for _, pattern := range p.Pipelines {
+		if pattern == AllPipelines {
+			return true
+		}
+		match, err := path.Match(pattern, pipelineID)
+		if err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// NewContext returns a context holding the principal.
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal of the context, or nil if the request
+// was not authenticated.
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+	return principal
+}
+
+// Authenticator identifies the caller of requests using API keys or JWT
+// bearer tokens.
+type Authenticator struct {
+	keys      map[string]*Principal
+	jwtKey    interface{}
+	jwtIssuer string
+}
+
+// NewAuthenticator loads the API keys and JWT verification key. A nil
+// authenticator is returned if authentication is disabled.
+func NewAuthenticator(config *env.Config) (*Authenticator, error) {
+	if !config.AuthEnabled {
+		log.Warnf("authentication is disabled so all routes are open")
+		return nil, nil
+	}
+
+	a := &Authenticator{
+		keys:      make(map[string]*Principal),
+		jwtIssuer: config.AuthJWTIssuer,
+	}
+	if config.AuthKeysFile != "" {
+		keys, err := loadKeys(config.AuthKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = keys
+		log.Infof("loaded %d API keys from '%s'", len(keys), config.AuthKeysFile)
+	}
+	if config.AuthJWTKeyFile != "" {
+		keyBytes, err := ioutil.ReadFile(config.AuthJWTKeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read JWT key file")
+		}
+		a.jwtKey, err = parseJWTKey(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("verifying JWT bearer tokens using key '%s'", config.AuthJWTKeyFile)
+	}
+	if len(a.keys) == 0 && a.jwtKey == nil {
+		return nil, errors.Errorf("authentication is enabled but neither API keys nor a JWT key are configured")
+	}
+
+	return a, nil
+}
+
+// Authenticate returns the principal identified by the credentials of the
+// request, or nil if the request has no credentials.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	return a.AuthenticateHeader(r.Header)
+}
+
+// AuthenticateHeader returns the principal identified by the credentials
+// found in the headers, or nil if there are none.
+func (a *Authenticator) AuthenticateHeader(header http.Header) (*Principal, error) {
+	if key := header.Get(APIKeyHeader); key != "" {
+		return a.authenticateKey(key)
+	}
+
+	authorization := header.Get("Authorization")
+	if authorization == "" {
+		return nil, nil
+	}
+	fields := strings.Fields(authorization)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		return nil, util.NewUnauthorizedError("unsupported authorization scheme")
+	}
+
+	// JWTs have three dot separated parts, anything else is an API key
+	token := fields[1]
+	if a.jwtKey != nil && strings.Count(token, ".") == 2 {
+		return a.authenticateJWT(token)
+	}
+	return a.authenticateKey(token)
+}
+
+func (a *Authenticator) authenticateKey(key string) (*Principal, error) {
+	principal, ok := a.keys[hashKey(key)]
+	if !ok {
+		return nil, util.NewUnauthorizedError("invalid API key")
+	}
+	return principal, nil
+}
+
+func hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
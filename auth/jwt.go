@@ -0,0 +1,174 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// jwtHeader is the JOSE header of a token.
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+}
+
+// jwtClaims are the claims used to build the principal. Scopes are space
+// separated as per RFC 8693.
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	Scope     string   `json:"scope"`
+	Pipelines []string `json:"pipelines"`
+}
+
+// parseJWTKey reads a PEM encoded RSA public key for RS256 tokens, or uses
+// the content as the shared secret of HS256 tokens.
+func parseJWTKey(keyBytes []byte) (interface{}, error) {
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		secret := bytes.TrimSpace(keyBytes)
+		if len(secret) == 0 {
+			return nil, errors.Errorf("JWT key file is empty")
+		}
+		return secret, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse JWT public key")
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("JWT public key must be an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+func (a *Authenticator) authenticateJWT(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+
+	header := &jwtHeader{}
+	err := decodeSegment(parts[0], header)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, util.NewUnauthorizedError("malformed JWT signature")
+	}
+	err = a.verifySignature(header.Algorithm, parts[0]+"."+parts[1], signature)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &jwtClaims{}
+	err = decodeSegment(parts[1], claims)
+	if err != nil {
+		return nil, err
+	}
+	err = a.validateClaims(claims, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// scopes meant for other services are ignored
+	scopes := []Scope{}
+	for _, s := range strings.Fields(claims.Scope) {
+		if validateScopes([]Scope{Scope(s)}) == nil {
+			scopes = append(scopes, Scope(s))
+		}
+	}
+
+	return &Principal{
+		Name:      claims.Subject,
+		Scopes:    scopes,
+		Pipelines: claims.Pipelines,
+	}, nil
+}
+
+func (a *Authenticator) verifySignature(algorithm string, signed string, signature []byte) error {
+	switch key := a.jwtKey.(type) {
+	case []byte:
+		if algorithm != "HS256" {
+			return util.NewUnauthorizedError("unsupported JWT algorithm '%s'", algorithm)
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return util.NewUnauthorizedError("invalid JWT signature")
+		}
+	case *rsa.PublicKey:
+		if algorithm != "RS256" {
+			return util.NewUnauthorizedError("unsupported JWT algorithm '%s'", algorithm)
+		}
+		hash := sha256.Sum256([]byte(signed))
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) != nil {
+			return util.NewUnauthorizedError("invalid JWT signature")
+		}
+	default:
+		return errors.Errorf("no JWT key configured")
+	}
+
+	return nil
+}
+
+func (a *Authenticator) validateClaims(claims *jwtClaims, now time.Time) error {
+	if claims.Subject == "" {
+		return util.NewUnauthorizedError("JWT has no subject")
+	}
+	if claims.ExpiresAt == nil {
+		return util.NewUnauthorizedError("JWT has no expiry")
+	}
+	if now.After(time.Unix(int64(*claims.ExpiresAt), 0)) {
+		return util.NewUnauthorizedError("JWT has expired")
+	}
+	if claims.NotBefore != nil && now.Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return util.NewUnauthorizedError("JWT is not valid yet")
+	}
+	if a.jwtIssuer != "" && claims.Issuer != a.jwtIssuer {
+		return util.NewUnauthorizedError("JWT issuer '%s' is not trusted", claims.Issuer)
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, output interface{}) error {
+	segmentBytes, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return util.NewUnauthorizedError("malformed JWT")
+	}
+	err = json.Unmarshal(segmentBytes, output)
+	if err != nil {
+		return util.NewUnauthorizedError("malformed JWT")
+	}
+	return nil
+}
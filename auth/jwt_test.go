@@ -0,0 +1,189 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	testSecret = "test-secret"
+)
+
+// signToken builds a token signed with the key, which is either an HMAC
+// secret or an RSA private key.
+func signToken(t *testing.T, algorithm string, key interface{}, claims map[string]interface{}) string {
+	encode := func(value interface{}) string {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("unable to marshal token segment: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(map[string]string{"alg": algorithm, "typ": "JWT"}) + "." + encode(claims)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		hash := sha256.Sum256([]byte(signed))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:])
+		if err != nil {
+			t.Fatalf("unable to sign token: %v", err)
+		}
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// rsaAuthenticator verifies tokens using the PEM encoded public key of the
+// private key, as read from the JWT key file.
+func rsaAuthenticator(t *testing.T, private *rsa.PrivateKey, issuer string) *Authenticator {
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to marshal public key: %v", err)
+	}
+	key, err := parseJWTKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("unable to parse public key: %v", err)
+	}
+	return &Authenticator{jwtKey: key, jwtIssuer: issuer}
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	secret, err := parseJWTKey([]byte(testSecret + "\n"))
+	if err != nil {
+		t.Fatalf("unable to parse secret: %v", err)
+	}
+	hmacAuth := &Authenticator{jwtKey: secret, jwtIssuer: "issuer"}
+	rsaAuth := rsaAuthenticator(t, private, "issuer")
+
+	now := time.Now()
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":       "client",
+			"iss":       "issuer",
+			"exp":       now.Add(time.Hour).Unix(),
+			"scope":     "read produce",
+			"pipelines": []string{"test"},
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(c, name)
+			} else {
+				c[name] = value
+			}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name          string
+		authenticator *Authenticator
+		token         string
+		scopes        []Scope
+	}{
+		{"hs256", hmacAuth, signToken(t, "HS256", []byte(testSecret), claims(nil)), []Scope{ScopeRead, ScopeProduce}},
+		{"rs256", rsaAuth, signToken(t, "RS256", private, claims(nil)), []Scope{ScopeRead, ScopeProduce}},
+		{"hs256 for rsa key", rsaAuth, signToken(t, "HS256", []byte(testSecret), claims(nil)), nil},
+		{"rs256 for secret", hmacAuth, signToken(t, "RS256", private, claims(nil)), nil},
+		{"none algorithm", hmacAuth, signToken(t, "none", nil, claims(nil)), nil},
+		{"hs256 other secret", hmacAuth, signToken(t, "HS256", []byte("other-secret"), claims(nil)), nil},
+		{"rs256 other key", rsaAuth, signToken(t, "RS256", other, claims(nil)), nil},
+		{"no expiry", hmacAuth, signToken(t, "HS256", []byte(testSecret), claims(map[string]interface{}{"exp": nil})), nil},
+		{"expired", hmacAuth, signToken(t, "HS256", []byte(testSecret), claims(map[string]interface{}{"exp": now.Add(-time.Minute).Unix()})), nil},
+		{"not valid yet", rsaAuth, signToken(t, "RS256", private, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})), nil},
+		{"valid since", rsaAuth, signToken(t, "RS256", private, claims(map[string]interface{}{"nbf": now.Add(-time.Minute).Unix()})), []Scope{ScopeRead, ScopeProduce}},
+		{"other issuer", hmacAuth, signToken(t, "HS256", []byte(testSecret), claims(map[string]interface{}{"iss": "other"})), nil},
+		{"no issuer", rsaAuth, signToken(t, "RS256", private, claims(map[string]interface{}{"iss": nil})), nil},
+		{"no subject", hmacAuth, signToken(t, "HS256", []byte(testSecret), claims(map[string]interface{}{"sub": nil})), nil},
+		{"foreign scopes", hmacAuth, signToken(t, "HS256", []byte(testSecret), claims(map[string]interface{}{"scope": "fit billing:read admin"})), []Scope{ScopeFit, ScopeAdmin}},
+		{"no known scopes", hmacAuth, signToken(t, "HS256", []byte(testSecret), claims(map[string]interface{}{"scope": "billing:read"})), []Scope{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Authorization", "Bearer "+test.token)
+			principal, err := test.authenticator.AuthenticateHeader(header)
+			if test.scopes == nil {
+				if err == nil {
+					t.Fatalf("expected the token to be rejected")
+				}
+				if kind, _ := util.GetErrorKind(err); kind != util.KindUnauthorized {
+					t.Errorf("expected an unauthorized error, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected the token to be accepted: %v", err)
+			}
+			if principal.Name != "client" || !reflect.DeepEqual(principal.Pipelines, []string{"test"}) {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+			if !reflect.DeepEqual(principal.Scopes, test.scopes) {
+				t.Errorf("expected scopes %v, got %v", test.scopes, principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestAuthenticateJWTWithoutIssuer(t *testing.T) {
+	secret, err := parseJWTKey([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("unable to parse secret: %v", err)
+	}
+	a := &Authenticator{jwtKey: secret}
+
+	token := signToken(t, "HS256", []byte(testSecret), map[string]interface{}{
+		"sub": "client",
+		"iss": "anyone",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	_, err = a.AuthenticateHeader(header)
+	if err != nil {
+		t.Errorf("expected any issuer to be accepted without a configured issuer: %v", err)
+	}
+}
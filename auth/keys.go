@@ -0,0 +1,87 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package auth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// apiKey is an entry of the API keys file. Keys should be stored as their
+// hex encoded SHA-256 hash rather than in clear.
+type apiKey struct {
+	Name      string   `json:"name"`
+	Key       string   `json:"key"`
+	KeyHash   string   `json:"keyHash"`
+	Scopes    []Scope  `json:"scopes"`
+	Pipelines []string `json:"pipelines"`
+}
+
+func loadKeys(filename string) (map[string]*Principal, error) {
+	keyBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read API keys file")
+	}
+
+	var entries []*apiKey
+	err = json.Unmarshal(keyBytes, &entries)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse API keys file")
+	}
+
+	keys := make(map[string]*Principal)
+	for i, e := range entries {
+		if e.Name == "" {
+			return nil, errors.Errorf("API key %d has no name", i)
+		}
+		hash := strings.ToLower(e.KeyHash)
+		if hash == "" {
+			if e.Key == "" {
+				return nil, errors.Errorf("API key '%s' has neither a key nor a key hash", e.Name)
+			}
+			hash = hashKey(e.Key)
+		}
+		if _, ok := keys[hash]; ok {
+			return nil, errors.Errorf("API key '%s' is a duplicate", e.Name)
+		}
+		err = validateScopes(e.Scopes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "API key '%s' is invalid", e.Name)
+		}
+
+		keys[hash] = &Principal{
+			Name:      e.Name,
+			Scopes:    e.Scopes,
+			Pipelines: e.Pipelines,
+		}
+	}
+
+	return keys, nil
+}
+
+func validateScopes(scopes []Scope) error {
+	for _, s := range scopes {
+		switch s {
+		case ScopeRead, ScopeProduce, ScopeFit, ScopeAdmin:
+		default:
+			return errors.Errorf("unknown scope '%s'", s)
+		}
+	}
+	return nil
+}
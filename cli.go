@@ -0,0 +1,345 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// stdout receives the results of the offline commands. It is kept apart from
+// the standard output of the process where supported, which is pointed at
+// stderr so logs and runner output do not end up mixed with the results.
+var stdout io.Writer = os.Stdout
+
+// command is a subcommand of the executable.
+type command struct {
+	description string
+	run         func(args []string) error
+}
+
+var commands = map[string]*command{
+	"serve":          {"start the http server (default)", serveCommand},
+	"fit":            {"fit a pipeline on a local dataset", fitCommand},
+	"produce":        {"produce predictions for a local dataset", produceCommand},
+	"format-dataset": {"format a local dataset as a D3M dataset folder", formatDatasetCommand},
+	"list-pipelines": {"list the uploaded pipelines", listPipelinesCommand},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s [command] [flags]\n\ncommands:\n", path.Base(os.Args[0]))
+	for _, name := range []string{"serve", "fit", "produce", "format-dataset", "list-pipelines"} {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, commands[name].description)
+	}
+	fmt.Fprintf(os.Stderr, "\nrun '%s [command] -h' for the flags of a command\n", path.Base(os.Args[0]))
+}
+
+// newFlagSet creates the flags of a command along with the flags shared by
+// all offline commands.
+func newFlagSet(name string) (*flag.FlagSet, *string, *bool) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	output := flags.String("output", "", "file to write the result to, defaults to stdout")
+	verbose := flags.Bool("verbose", false, "log progress along with the result")
+	return flags, output, verbose
+}
+
+// setup loads the configuration and initializes the shared state, returning
+// a function to call once the command completes. Offline commands only log
+// warnings unless verbose since the log shares stdout with the result.
+func setup(verbose bool) (*env.Config, func(), error) {
+	if !verbose {
+		log.SetLevel(log.WarnLevel)
+	}
+	log.Infof("version: %s built: %s", version, timestamp)
+
+	// load config from env
+	config, err := env.LoadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Infof("%+v", spew.Sdump(config))
+	env.Initialize(&config)
+	util.SetConfig(&config)
+
+	// set up trace exports
+	shutdownTracing, err := tracing.Initialize(&config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &config, shutdownTracing, nil
+}
+
+// setupOffline prepares an offline command, sending anything written to the
+// standard output of the process to stderr before setting up as usual.
+func setupOffline(verbose bool) (*env.Config, func(), error) {
+	var err error
+	stdout, err = redirectStdout()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return setup(verbose)
+}
+
+// readDataset reads a json or csv file as the type of dataset expected by the
+// pipeline, using the file extension to determine the format.
+func readDataset(ctx context.Context, pipelineID string, filename string) (task.DatasetConstructor, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read dataset '%s'", filename)
+	}
+
+	format := task.FormatJSON
+	if strings.EqualFold(path.Ext(filename), ".csv") {
+		format = task.FormatCSV
+	}
+
+	return task.ParseDataset(ctx, pipelineID, data, format)
+}
+
+// writeOutput writes the result to the output file, or stdout if none.
+func writeOutput(output string, data []byte) error {
+	if output == "" {
+		_, err := stdout.Write(data)
+		return err
+	}
+
+	err := ioutil.WriteFile(output, data, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "unable to write output to '%s'", output)
+	}
+	return nil
+}
+
+func writeJSON(output string, result interface{}) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal result into JSON")
+	}
+	return writeOutput(output, append(data, '\n'))
+}
+
+// requireFlags exits with the usage of the command if any of the flags is
+// missing, matching how the flag package reports invalid flags.
+func requireFlags(flags *flag.FlagSet, names ...string) {
+	for _, name := range names {
+		if flags.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(flags.Output(), "flag is required: -%s\n", name)
+			flags.Usage()
+			os.Exit(2)
+		}
+	}
+}
+
+// fitCommand fits a pipeline using a local labelled dataset.
+func fitCommand(args []string) error {
+	flags, output, verbose := newFlagSet("fit")
+	pipelineID := flags.String("pipeline", "", "id of the pipeline to fit")
+	input := flags.String("input", "", "json or csv file holding the labelled data")
+	flags.Parse(args)
+	requireFlags(flags, "pipeline", "input")
+
+	config, shutdown, err := setupOffline(*verbose)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+	ctx := context.Background()
+
+	ds, err := readDataset(ctx, *pipelineID, *input)
+	if err != nil {
+		return err
+	}
+	schemaPath, err := task.CreateDataset(ctx, *pipelineID, ds)
+	if err != nil {
+		return err
+	}
+	runID, err := task.Fit(ctx, *pipelineID, schemaPath, ds.GetPredictionsID(), config)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(*output, map[string]interface{}{
+		"pipelineId":   *pipelineID,
+		"predictionId": ds.GetPredictionsID(),
+		"fitted":       true,
+		"runId":        runID,
+	})
+}
+
+// produceCommand produces predictions for a local dataset using a fitted
+// pipeline, writing them as json or as csv.
+func produceCommand(args []string) error {
+	flags, output, verbose := newFlagSet("produce")
+	pipelineID := flags.String("pipeline", "", "id of the fitted pipeline")
+	input := flags.String("input", "", "json or csv file holding the unlabelled data")
+	format := flags.String("format", "json", "format of the result, either json or csv (predictions only)")
+	flags.Parse(args)
+	requireFlags(flags, "pipeline", "input")
+	if *format != "json" && *format != "csv" {
+		return errors.Errorf("unsupported output format '%s'", *format)
+	}
+
+	config, shutdown, err := setupOffline(*verbose)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+	ctx := context.Background()
+
+	ds, err := readDataset(ctx, *pipelineID, *input)
+	if err != nil {
+		return err
+	}
+	if !task.IsFitted(*pipelineID) {
+		return util.WithKind(errors.Errorf("pipeline '%s' has not been fitted", *pipelineID), util.KindConflict)
+	}
+	schemaPath, err := task.CreateDataset(ctx, *pipelineID, ds)
+	if err != nil {
+		return err
+	}
+	data, err := task.ReadLearningData(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	queue := task.NewQueue(config.BatchWorkers)
+	err = task.EnqueueRows(ctx, queue, ds.GetPredictionsID(), 0, data)
+	if err != nil {
+		return err
+	}
+	predictions, err := task.ProduceBatch(ctx, *pipelineID, schemaPath, ds.GetPredictionsID(), queue, config)
+	if err != nil {
+		return err
+	}
+	for _, f := range predictions.Failures {
+		log.Warnf("unable to produce predictions for row '%s': %s", f.ID, f.Error)
+	}
+
+	if config.ClearDataset {
+		err = task.ClearDataset(*pipelineID, ds.GetPredictionsID())
+		if err != nil {
+			return err
+		}
+	}
+
+	if *format == "csv" {
+		buffer := &bytes.Buffer{}
+		writer := csv.NewWriter(buffer)
+		if result := predictions.Predictions(); result != nil {
+			err = writer.Write(result.Header)
+			if err != nil {
+				return errors.Wrap(err, "unable to write predictions header as csv")
+			}
+			err = writer.WriteAll(result.Data)
+			if err != nil {
+				return errors.Wrap(err, "unable to write predictions as csv")
+			}
+		}
+		return writeOutput(*output, buffer.Bytes())
+	}
+
+	parsed, err := task.ParsePredictions(predictions.Predictions())
+	if err != nil {
+		return err
+	}
+	outputs := make(map[string]*task.Output)
+	for _, o := range predictions.Outputs {
+		outputs[o.Name] = o
+	}
+
+	return writeJSON(*output, map[string]interface{}{
+		"pipelineId":   *pipelineID,
+		"predictionId": ds.GetPredictionsID(),
+		"predictions":  parsed,
+		"outputs":      outputs,
+		"runIds":       predictions.RunIDs,
+		"failures":     predictions.Failures,
+	})
+}
+
+// formatDatasetCommand converts a local json or csv file into a D3M dataset
+// folder matching the schema of a pipeline.
+func formatDatasetCommand(args []string) error {
+	flags, output, verbose := newFlagSet("format-dataset")
+	pipelineID := flags.String("pipeline", "", "id of the pipeline whose schema the dataset should match")
+	input := flags.String("input", "", "json or csv file holding the data")
+	directory := flags.String("dataset", "", "folder to write the D3M dataset to")
+	flags.Parse(args)
+	requireFlags(flags, "pipeline", "input", "dataset")
+
+	_, shutdown, err := setupOffline(*verbose)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+	ctx := context.Background()
+
+	ds, err := readDataset(ctx, *pipelineID, *input)
+	if err != nil {
+		return err
+	}
+	schemaPath, err := task.FormatDataset(ctx, *pipelineID, ds, *directory)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(*output, map[string]interface{}{
+		"pipelineId":  *pipelineID,
+		"datasetPath": *directory,
+		"schemaPath":  schemaPath,
+	})
+}
+
+// listPipelinesCommand lists the pipelines found in the pipeline folder.
+func listPipelinesCommand(args []string) error {
+	flags, output, verbose := newFlagSet("list-pipelines")
+	flags.Parse(args)
+
+	config, shutdown, err := setupOffline(*verbose)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	pipelines, err := task.GetPipelines(config.PipelineDir)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(*output, map[string]interface{}{
+		"pipelines": pipelines,
+	})
+}
@@ -0,0 +1,277 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/dataset"
+	"github.com/uncharted-distil/distil-pipeline-executer/routes"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+)
+
+// FitOptions are the optional settings of a fit request.
+type FitOptions struct {
+	// Validation is the validation method, either holdout or kfold.
+	Validation   string
+	HoldoutRatio float64
+	Folds        int
+	Seed         int64
+	// Candidate fits a candidate pipeline only promoted if it scores better.
+	Candidate bool
+	Metric    string
+	Threshold *float64
+	// Training accumulates the training data, either append or replace.
+	Training string
+}
+
+// FitResponse is the result of a fit request.
+type FitResponse struct {
+	PipelineID   string                 `json:"pipelineId"`
+	PredictionID string                 `json:"predictionId"`
+	Fitted       bool                   `json:"fitted"`
+	RunID        string                 `json:"runId,omitempty"`
+	TrainingRows int                    `json:"trainingRows,omitempty"`
+	Validation   *task.ValidationResult `json:"validation,omitempty"`
+	Candidate    *task.CandidateResult  `json:"candidate,omitempty"`
+	JobID        string                 `json:"-"`
+}
+
+// ProduceOptions are the optional settings of produce and score requests.
+type ProduceOptions struct {
+	// Outputs lists the pipeline outputs to return by name or key.
+	Outputs []string
+	// Priority is the admission priority, either bulk or interactive.
+	Priority string
+}
+
+// ProduceResponse is the result of a produce request.
+type ProduceResponse struct {
+	PipelineID   string                  `json:"pipelineId"`
+	PredictionID string                  `json:"predictionId"`
+	Predictions  []*routes.Prediction    `json:"predictions"`
+	Outputs      map[string]*task.Output `json:"outputs"`
+	RunIDs       []string                `json:"runIds"`
+	Failures     []*task.RowFailure      `json:"failures"`
+	JobID        string                  `json:"-"`
+}
+
+// ScoreResponse is the result of a score request.
+type ScoreResponse struct {
+	PipelineID   string `json:"pipelineId"`
+	PredictionID string `json:"predictionId"`
+	task.ScoreResult
+	Failures []*task.RowFailure `json:"failures"`
+	JobID    string             `json:"-"`
+}
+
+// Pipelines lists the pipelines the client can access.
+func (c *Client) Pipelines(ctx context.Context) ([]*task.PipelineInfo, error) {
+	pipelines := make([]*task.PipelineInfo, 0)
+	_, err := c.do(ctx, http.MethodGet, "/distil/pipelines", nil, nil, &pipelines)
+	if err != nil {
+		return nil, err
+	}
+	return pipelines, nil
+}
+
+// Job returns the status of the job tracking a fit, produce or score request.
+func (c *Client) Job(ctx context.Context, jobID string) (*task.Job, error) {
+	job := &task.Job{}
+	_, err := c.do(ctx, http.MethodGet, pipelinePath("/distil/jobs", jobID), nil, nil, job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Upload stores a pipeline along with the schema of its dataset and its
+// problem, replacing the existing pipeline if overwrite is set.
+func (c *Client) Upload(ctx context.Context, pipelineID string, upload *routes.PipelineUpload, overwrite bool) error {
+	query := url.Values{"overwrite": {strconv.FormatBool(overwrite)}}
+	_, err := c.do(ctx, http.MethodPost, pipelinePath("/distil/upload", pipelineID), query, jsonBody(upload), nil)
+	return err
+}
+
+// UploadFitted stores a fitted pipeline, streaming it from the reader. The
+// upload is only retried if the reader can seek back to its start.
+func (c *Client) UploadFitted(ctx context.Context, pipelineID string, fitted io.Reader) error {
+	body := func(attempt int) (io.Reader, string, error) {
+		if attempt > 0 {
+			seeker, ok := fitted.(io.Seeker)
+			if !ok {
+				return nil, "", errNotReplayable
+			}
+			_, err := seeker.Seek(0, io.SeekStart)
+			if err != nil {
+				return nil, "", errors.Wrap(err, "unable to rewind fitted pipeline")
+			}
+		}
+
+		reader, writer := io.Pipe()
+		form := multipart.NewWriter(writer)
+		go func() {
+			part, err := form.CreateFormFile("file", "pipeline.d3m")
+			if err == nil {
+				_, err = io.Copy(part, fitted)
+			}
+			if err == nil {
+				err = form.Close()
+			}
+			writer.CloseWithError(err)
+		}()
+		return reader, form.FormDataContentType(), nil
+	}
+
+	query := url.Values{"type": {"fitted"}}
+	_, err := c.do(ctx, http.MethodPost, pipelinePath("/distil/upload", pipelineID), query, body, nil)
+	return err
+}
+
+// Fit trains the pipeline using the labelled data, which is either a
+// *dataset.Table or a *dataset.Image.
+func (c *Client) Fit(ctx context.Context, pipelineID string, data interface{}, options *FitOptions) (*FitResponse, error) {
+	result := &FitResponse{}
+	header, err := c.do(ctx, http.MethodPost, pipelinePath("/distil/fit", pipelineID), fitQuery(options), jsonBody(data), result)
+	if err != nil {
+		return nil, err
+	}
+	result.JobID = header.Get(routes.JobIDHeader)
+	return result, nil
+}
+
+// Produce generates predictions for the data, which is either a
+// *dataset.Table or a *dataset.Image, using the fitted pipeline.
+func (c *Client) Produce(ctx context.Context, pipelineID string, data interface{}, options *ProduceOptions) (*ProduceResponse, error) {
+	result := &ProduceResponse{}
+	header, err := c.do(ctx, http.MethodPost, pipelinePath("/distil/produce", pipelineID), produceQuery(options), jsonBody(data), result)
+	if err != nil {
+		return nil, err
+	}
+	result.JobID = header.Get(routes.JobIDHeader)
+	return result, nil
+}
+
+// Score generates predictions for the labelled data, which is either a
+// *dataset.Table or a *dataset.Image, and evaluates them.
+func (c *Client) Score(ctx context.Context, pipelineID string, data interface{}, options *ProduceOptions) (*ScoreResponse, error) {
+	result := &ScoreResponse{}
+	header, err := c.do(ctx, http.MethodPost, pipelinePath("/distil/score", pipelineID), produceQuery(options), jsonBody(data), result)
+	if err != nil {
+		return nil, err
+	}
+	result.JobID = header.Get(routes.JobIDHeader)
+	return result, nil
+}
+
+// ProduceStream generates predictions for the rows received on the channel,
+// sending them as they arrive in requests of at most chunkSize rows. The
+// response of every request is passed to the handler, stopping at the first
+// error. It returns once the channel is closed and every row was produced.
+func (c *Client) ProduceStream(ctx context.Context, pipelineID string, columns []string, rows <-chan dataset.Row, chunkSize int, options *ProduceOptions, handle func(*ProduceResponse) error) error {
+	if chunkSize <= 0 {
+		return errors.Errorf("chunk size must be positive")
+	}
+
+	flush := func(chunk []dataset.Row) error {
+		result, err := c.Produce(ctx, pipelineID, &dataset.Table{Columns: columns, Rows: chunk}, options)
+		if err != nil {
+			return err
+		}
+		return handle(result)
+	}
+
+	chunk := make([]dataset.Row, 0, chunkSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case row, ok := <-rows:
+			if !ok {
+				if len(chunk) == 0 {
+					return nil
+				}
+				return flush(chunk)
+			}
+			chunk = append(chunk, row)
+			if len(chunk) == chunkSize {
+				err := flush(chunk)
+				if err != nil {
+					return err
+				}
+				chunk = make([]dataset.Row, 0, chunkSize)
+			}
+		}
+	}
+}
+
+func fitQuery(options *FitOptions) url.Values {
+	query := url.Values{}
+	if options == nil {
+		return query
+	}
+
+	if options.Validation != "" {
+		query.Set("validation", options.Validation)
+	}
+	if options.HoldoutRatio > 0 {
+		query.Set("holdout", strconv.FormatFloat(options.HoldoutRatio, 'f', -1, 64))
+	}
+	if options.Folds > 0 {
+		query.Set("folds", strconv.Itoa(options.Folds))
+	}
+	if options.Seed != 0 {
+		query.Set("seed", strconv.FormatInt(options.Seed, 10))
+	}
+	if options.Candidate {
+		query.Set("mode", "candidate")
+	}
+	if options.Metric != "" {
+		query.Set("metric", options.Metric)
+	}
+	if options.Threshold != nil {
+		query.Set("threshold", strconv.FormatFloat(*options.Threshold, 'f', -1, 64))
+	}
+	if options.Training != "" {
+		query.Set("training", options.Training)
+	}
+
+	return query
+}
+
+func produceQuery(options *ProduceOptions) url.Values {
+	query := url.Values{}
+	if options == nil {
+		return query
+	}
+
+	if len(options.Outputs) > 0 {
+		query.Set("outputs", strings.Join(options.Outputs, ","))
+	}
+	if options.Priority != "" {
+		query.Set("priority", options.Priority)
+	}
+
+	return query
+}
@@ -0,0 +1,250 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
+	"github.com/uncharted-distil/distil-pipeline-executer/routes"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryWait  = 500 * time.Millisecond
+	maxRetryWait      = 30 * time.Second
+)
+
+var (
+	errNotReplayable = errors.New("request body cannot be sent again")
+)
+
+// Client calls the executer API. Requests rejected because the service is
+// busy or rate limited are retried with exponential backoff, honouring the
+// Retry-After header when present. Requests that may have reached the
+// service before failing, ie: on connection errors or gateway timeouts, are
+// only retried if sending them again is harmless.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	APIKey     string
+	Token      string
+	MaxRetries int
+	RetryWait  time.Duration
+}
+
+// Error is returned when the service responds with an error status.
+type Error struct {
+	StatusCode int         `json:"-"`
+	Code       string      `json:"code"`
+	Message    string      `json:"message"`
+	Details    interface{} `json:"details,omitempty"`
+	RequestID  string      `json:"requestId,omitempty"`
+}
+
+// Error returns the message reported by the service.
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%s (%d)", e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// bodyFunc creates the body of a request along with its content type. It is
+// called once per attempt so bodies can be streamed.
+type bodyFunc func(attempt int) (io.Reader, string, error)
+
+// NewClient creates a client for the service found at the base url, ie:
+// http://localhost:8080.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		MaxRetries: defaultMaxRetries,
+		RetryWait:  defaultRetryWait,
+	}
+}
+
+// do sends the request, retrying when allowed, and decodes the json response
+// into the result. The headers of the response are returned.
+func (c *Client) do(ctx context.Context, method string, route string, query url.Values, body bodyFunc, result interface{}) (http.Header, error) {
+	endpoint := c.BaseURL + route
+	if len(query) > 0 {
+		endpoint = endpoint + "?" + query.Encode()
+	}
+
+	idempotent := isIdempotent(method, route)
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		header, retryAfter, err := c.send(ctx, method, endpoint, body, attempt, idempotent, result)
+		if err == errNotReplayable {
+			return nil, lastErr
+		}
+		lastErr = err
+		if err == nil || retryAfter < 0 || attempt >= c.MaxRetries {
+			return header, err
+		}
+
+		// back off exponentially unless the service says when to come back
+		wait := retryAfter
+		if wait == 0 {
+			wait = c.RetryWait << uint(attempt)
+		}
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), err.Error())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send makes a single attempt at the request. The returned wait is negative
+// if the request should not be retried and 0 if no wait was requested.
+func (c *Client) send(ctx context.Context, method string, endpoint string, body bodyFunc, attempt int, idempotent bool, result interface{}) (http.Header, time.Duration, error) {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		var err error
+		reader, contentType, err = body(attempt)
+		if err != nil {
+			return nil, -1, err
+		}
+	}
+
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return nil, -1, errors.Wrap(err, "unable to create request")
+	}
+	req = req.WithContext(ctx)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.APIKey != "" {
+		req.Header.Set(auth.APIKeyHeader, c.APIKey)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, -1, errors.Wrapf(err, "request to '%s' cancelled", endpoint)
+		}
+		// the request may have been received before the connection failed
+		wait := time.Duration(-1)
+		if idempotent {
+			wait = 0
+		}
+		return nil, wait, errors.Wrapf(err, "unable to send request to '%s'", endpoint)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return res.Header, retryWait(res, idempotent), readError(res)
+	}
+
+	// decode straight from the body to avoid buffering large responses
+	if result != nil {
+		err = json.NewDecoder(res.Body).Decode(result)
+		if err != nil {
+			return res.Header, -1, errors.Wrapf(err, "unable to decode response from '%s'", endpoint)
+		}
+	}
+
+	return res.Header, -1, nil
+}
+
+// isIdempotent returns true if sending the request more than once has the
+// same effect as sending it once. Fit appends to the training data and
+// produce and score start jobs, while uploads replace what they stored.
+func isIdempotent(method string, route string) bool {
+	return method == http.MethodGet || strings.HasPrefix(route, "/distil/upload/")
+}
+
+// retryWait returns how long to wait before retrying a failed request, or -1
+// if the failure is not transient. The service rejects requests with 429 and
+// 503 before running them, whereas a gateway error can hide a request that
+// is still running.
+func retryWait(res *http.Response, idempotent bool) time.Duration {
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		if !idempotent {
+			return -1
+		}
+	default:
+		return -1
+	}
+
+	seconds, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func readError(res *http.Response) error {
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read error response")
+	}
+
+	apiErr := &Error{StatusCode: res.StatusCode}
+	err = json.Unmarshal(body, apiErr)
+	if err != nil || apiErr.Message == "" {
+		// not an error generated by the service, ie: a proxy error page
+		apiErr.Message = strings.TrimSpace(string(body))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(res.StatusCode)
+		}
+	}
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = res.Header.Get(routes.RequestIDHeader)
+	}
+
+	return apiErr
+}
+
+// jsonBody streams the json encoding of the value as the request body.
+func jsonBody(value interface{}) bodyFunc {
+	return func(attempt int) (io.Reader, string, error) {
+		reader, writer := io.Pipe()
+		go func() {
+			writer.CloseWithError(json.NewEncoder(writer).Encode(value))
+		}()
+		return reader, "application/json", nil
+	}
+}
+
+// pipelinePath builds the route for the pipeline.
+func pipelinePath(route string, pipelineID string) string {
+	return fmt.Sprintf("%s/%s", route, url.PathEscape(pipelineID))
+}
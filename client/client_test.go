@@ -0,0 +1,251 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/dataset"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/internal/testrunner"
+	"github.com/uncharted-distil/distil-pipeline-executer/routes"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+var (
+	server *httptest.Server
+)
+
+func TestMain(m *testing.M) {
+	testrunner.RunIfRunner()
+
+	dir, err := ioutil.TempDir("", "client-test")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	log.SetLevel(log.WarnLevel)
+	config, err := env.LoadConfig()
+	if err != nil {
+		panic(err)
+	}
+	config.PipelineDir = path.Join(dir, "pipelines")
+	config.DatasetDir = path.Join(dir, "datasets")
+	config.PredictionDir = path.Join(dir, "predictions")
+	config.RunDir = path.Join(dir, "runs")
+	config.JobDir = path.Join(dir, "jobs")
+	err = testrunner.Configure(&config)
+	if err != nil {
+		panic(err)
+	}
+	env.Initialize(&config)
+	util.SetConfig(&config)
+
+	jobs, err := task.NewJobStore(config.JobDir)
+	if err != nil {
+		panic(err)
+	}
+	server = httptest.NewServer(routes.NewMux(&config, routes.Routes(&config, task.NewQueue(0), jobs, "test", "test")))
+	code := m.Run()
+	server.Close()
+
+	os.Exit(code)
+}
+
+func newTestClient(baseURL string) *Client {
+	c := NewClient(baseURL)
+	c.RetryWait = time.Millisecond
+	return c
+}
+
+func uploadPipeline(t *testing.T, c *Client, pipelineID string) {
+	err := c.Upload(context.Background(), pipelineID, &routes.PipelineUpload{
+		DatasetSchema: json.RawMessage(testrunner.DatasetSchema),
+		Pipeline:      json.RawMessage(testrunner.Pipeline),
+		Problem:       json.RawMessage(testrunner.Problem),
+	}, true)
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+}
+
+func table(labels ...string) *dataset.Table {
+	rows := make([]dataset.Row, 0)
+	for i := 0; i+1 < len(labels); i += 2 {
+		rows = append(rows, dataset.Row{
+			ID:   string(rune('a' + i/2)),
+			Data: map[string]interface{}{"x": labels[i], "y": labels[i+1]},
+		})
+	}
+	return &dataset.Table{Rows: rows}
+}
+
+func TestFitProduceScore(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(server.URL)
+	uploadPipeline(t, c, "fit-produce")
+
+	fit, err := c.Fit(ctx, "fit-produce", table("cat", "cat", "dog", "dog"), nil)
+	if err != nil {
+		t.Fatalf("fit failed: %v", err)
+	}
+	if !fit.Fitted || fit.JobID == "" {
+		t.Errorf("expected a fitted pipeline and a job id, got %+v", fit)
+	}
+
+	produced, err := c.Produce(ctx, "fit-produce", table("cat", "", "bird", ""), nil)
+	if err != nil {
+		t.Fatalf("produce failed: %v", err)
+	}
+	if len(produced.Predictions) != 2 || produced.JobID == "" {
+		t.Fatalf("expected 2 predictions and a job id, got %+v", produced)
+	}
+	for _, p := range produced.Predictions {
+		if p.Value == "" {
+			t.Errorf("expected a predicted value for row '%s'", p.ID)
+		}
+	}
+
+	scored, err := c.Score(ctx, "fit-produce", table("cat", "cat", "dog", "cat"), nil)
+	if err != nil {
+		t.Fatalf("score failed: %v", err)
+	}
+	accuracy := scored.GetScore("accuracy")
+	if accuracy == nil || accuracy.Value != 0.5 || scored.Count != 2 {
+		t.Errorf("expected an accuracy of 0.5 over 2 rows, got %+v", scored.ScoreResult)
+	}
+
+	job, err := c.Job(ctx, produced.JobID)
+	if err != nil {
+		t.Fatalf("job lookup failed: %v", err)
+	}
+	if job.Status != task.JobSucceeded {
+		t.Errorf("expected the produce job to have succeeded, got %s", job.Status)
+	}
+}
+
+func TestUploadFitted(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(server.URL)
+	uploadPipeline(t, c, "upload-fitted")
+
+	err := c.UploadFitted(ctx, "upload-fitted", bytes.NewReader([]byte("uploaded")))
+	if err != nil {
+		t.Fatalf("fitted upload failed: %v", err)
+	}
+	fitted, err := ioutil.ReadFile(env.ResolvePipelineD3MPath("upload-fitted"))
+	if err != nil || string(fitted) != "uploaded" {
+		t.Errorf("expected the fitted pipeline to be stored, got '%s' (%v)", fitted, err)
+	}
+
+	pipelines, err := c.Pipelines(ctx)
+	if err != nil {
+		t.Fatalf("listing pipelines failed: %v", err)
+	}
+	found := false
+	for _, p := range pipelines {
+		found = found || (p.PipelineID == "upload-fitted" && p.Fitted)
+	}
+	if !found {
+		t.Errorf("expected the fitted pipeline to be listed")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var attempts int32
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer limited.Close()
+
+	uploadPipeline(t, newTestClient(server.URL), "retry-after")
+	c := newTestClient(limited.URL)
+	start := time.Now()
+	_, err := c.Produce(context.Background(), "retry-after", table("cat", ""), nil)
+	// the pipeline is not fitted, which is only reported once let through
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.StatusCode == http.StatusTooManyRequests {
+		t.Fatalf("expected the request to be retried until let through, got %v", err)
+	}
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Errorf("expected 3 attempts, got %d", atomic.LoadInt32(&attempts))
+	}
+	if time.Since(start) < 2*time.Second {
+		t.Errorf("expected the Retry-After header to be honoured")
+	}
+}
+
+func TestRetryConnectionErrors(t *testing.T) {
+	var attempts int32
+	dropped := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer dropped.Close()
+	c := newTestClient(dropped.URL)
+
+	_, err := c.Fit(context.Background(), "dropped", table("cat", "cat"), nil)
+	if err == nil || atomic.LoadInt32(&attempts) != 1 {
+		t.Errorf("expected a fit to fail without being sent again, got %d attempts (%v)", attempts, err)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	_, err = c.Pipelines(context.Background())
+	if err == nil || atomic.LoadInt32(&attempts) != int32(c.MaxRetries+1) {
+		t.Errorf("expected listing pipelines to be retried, got %d attempts (%v)", attempts, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := newTestClient(server.URL)
+
+	_, err := c.Produce(context.Background(), "missing", table("cat", ""), nil)
+	apiErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Code != routes.ErrorCodeNotFound || apiErr.RequestID == "" {
+		t.Errorf("expected a not found error with a request id, got %+v", apiErr)
+	}
+
+	_, err = c.Produce(context.Background(), "missing", map[string]string{"rows": "invalid"}, nil)
+	apiErr, ok = err.(*Error)
+	if !ok || apiErr.StatusCode/100 != 4 || apiErr.Details == nil {
+		t.Errorf("expected a client error detailing the invalid body, got %v", err)
+	}
+}
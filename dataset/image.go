@@ -29,6 +29,7 @@ import (
 	"github.com/pkg/errors"
 
 	cm "github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
 	"github.com/uncharted-distil/distil-pipeline-executer/model"
 	"github.com/uncharted-distil/distil-pipeline-executer/util"
 )
@@ -52,8 +53,9 @@ func NewImageDataset(rawData []byte) (*Image, error) {
 	images := &Image{}
 	err := json.Unmarshal(rawData, images)
 	if err != nil {
-		return nil, errors.Wrapf(err, "unable to parse json")
+		return nil, util.WithKind(errors.Wrapf(err, "unable to parse json"), util.KindBadRequest)
 	}
+	images.ID = safePredictionsID(images.ID)
 
 	return images, nil
 }
@@ -63,6 +65,11 @@ func (i *Image) CreateDataset(rootPath string) (*model.Dataset, error) {
 	learningData := make([][]string, len(i.Images))
 	mediaPath := path.Join(rootPath, "media")
 	for index, im := range i.Images {
+		// the image id is used as file name
+		if !env.IsValidID(im.ID) {
+			return nil, util.WithKind(env.ValidateID(im.ID), util.KindInvalid)
+		}
+
 		// read the image into memory
 		img, err := im.read()
 		if err != nil {
@@ -102,7 +109,7 @@ func (i *ImageEncoded) read() (image.Image, error) {
 	// decode the image
 	imageRaw, err := base64.StdEncoding.DecodeString(i.Image)
 	if err != nil {
-		return nil, errors.Wrapf(err, "unable to decode image '%s'", i.ID)
+		return nil, util.WithKind(errors.Wrapf(err, "unable to decode image '%s'", i.ID), util.KindBadRequest)
 	}
 
 	switch i.Type {
@@ -111,7 +118,7 @@ func (i *ImageEncoded) read() (image.Image, error) {
 	case "jpg", "jpeg":
 		return jpeg.Decode(bytes.NewReader(imageRaw))
 	default:
-		return nil, errors.Errorf("unsupported image type '%s'", i.Type)
+		return nil, util.NewInvalidError("unsupported image type '%s'", i.Type)
 	}
 }
 
@@ -16,66 +16,122 @@
 package dataset
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"sort"
+	"strconv"
 
 	"github.com/pkg/errors"
 
+	cm "github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
 	"github.com/uncharted-distil/distil-pipeline-executer/model"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
 )
 
-// Table represents a basic table dataset.
+// Table represents a basic table dataset. Columns is optional and, when
+// provided, fixes the order of the columns in the learning data. Otherwise
+// the columns are derived from the row data and sorted by name.
 type Table struct {
-	ID   string `json:"id"`
-	Rows []Row  `json:"rows"`
+	ID      string   `json:"id"`
+	Columns []string `json:"columns,omitempty"`
+	Rows    []Row    `json:"rows"`
 }
 
-// Row is a row of table data, tagged with an id.
+// Row is a row of table data, tagged with an id. Values can be strings,
+// numbers, booleans or null.
 type Row struct {
-	ID   string            `json:"id"`
-	Data map[string]string `json:"data"`
+	ID   string                 `json:"id"`
+	Data map[string]interface{} `json:"data"`
 }
 
 // NewTableDataset creates a new table dataset from raw byte data, assuming json
 func NewTableDataset(rawData []byte) (*Table, error) {
 	table := &Table{}
-	err := json.Unmarshal(rawData, table)
+	decoder := json.NewDecoder(bytes.NewReader(rawData))
+	decoder.UseNumber()
+	err := decoder.Decode(table)
 	if err != nil {
-		return nil, errors.Wrapf(err, "unable to parse json")
+		return nil, util.WithKind(errors.Wrapf(err, "unable to parse json"), util.KindBadRequest)
 	}
+	table.ID = safePredictionsID(table.ID)
 
 	return table, nil
 }
 
+// NewTableDatasetFromCSV creates a new table dataset from csv data with a
+// header row. The d3m index column, if present, is used as the row id. An
+// id is generated if none is provided.
+func NewTableDatasetFromCSV(id string, rawData []byte) (*Table, error) {
+	if id == "" {
+		id = env.NewID()
+	}
+	records, err := csv.NewReader(bytes.NewReader(rawData)).ReadAll()
+	if err != nil {
+		return nil, util.WithKind(errors.Wrapf(err, "unable to parse csv"), util.KindBadRequest)
+	}
+	if len(records) == 0 {
+		return nil, util.NewInvalidError("csv data has no header row")
+	}
+
+	header := records[0]
+	columns := make([]string, 0, len(header))
+	for _, c := range header {
+		if c != cm.D3MIndexName {
+			columns = append(columns, c)
+		}
+	}
+
+	rows := make([]Row, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := Row{Data: make(map[string]interface{})}
+		for i, value := range record {
+			if header[i] == cm.D3MIndexName {
+				row.ID = value
+			} else {
+				row.Data[header[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return &Table{
+		ID:      safePredictionsID(id),
+		Columns: columns,
+		Rows:    rows,
+	}, nil
+}
+
 // CreateDataset processes the table data structure into a dataset that can
 // be used in the D3M ecosystem.
 func (t *Table) CreateDataset(rootPath string) (*model.Dataset, error) {
-	// get the list of fields on the first pass
-	fieldMap := map[string]int{"d3mIndex": 0}
-	for _, row := range t.Rows {
-		for f := range row.Data {
-			if fieldMap[f] == 0 {
-				fieldMap[f] = len(fieldMap)
-			}
-		}
+	columns, err := t.getColumns()
+	if err != nil {
+		return nil, err
+	}
+	fieldMap := make(map[string]int)
+	for i, c := range columns {
+		fieldMap[c] = i
 	}
 
-	// create the learning data using the field map
+	// create the learning data using the field map, leaving missing fields empty
 	learningData := make([][]string, 0)
 	for _, row := range t.Rows {
-		entry := make([]string, len(fieldMap))
-		entry[0] = row.ID
+		entry := make([]string, len(columns))
 		for f, d := range row.Data {
-			entry[fieldMap[f]] = d
+			value, err := formatValue(d)
+			if err != nil {
+				return nil, util.WithKind(errors.Wrapf(err, "unable to format field '%s' of row '%s'", f, row.ID), util.KindInvalid)
+			}
+			entry[fieldMap[f]] = value
+		}
+		if row.ID != "" {
+			entry[0] = row.ID
 		}
 		learningData = append(learningData, entry)
 	}
 
-	// get the columns
-	columns := make([]string, len(fieldMap))
-	for f, c := range fieldMap {
-		columns[c] = f
-	}
-
 	return &model.Dataset{
 		ID:        t.ID,
 		Variables: columns,
@@ -87,3 +143,70 @@ func (t *Table) CreateDataset(rootPath string) (*model.Dataset, error) {
 func (t *Table) GetPredictionsID() string {
 	return t.ID
 }
+
+// getColumns returns the ordered list of columns, with the d3m index always
+// being the first column.
+func (t *Table) getColumns() ([]string, error) {
+	columns := []string{cm.D3MIndexName}
+	if len(t.Columns) > 0 {
+		// use the explicit schema, making sure the data fits it
+		known := map[string]bool{cm.D3MIndexName: true}
+		for _, c := range t.Columns {
+			if known[c] {
+				if c == cm.D3MIndexName {
+					continue
+				}
+				return nil, util.NewInvalidError("column '%s' listed more than once", c)
+			}
+			known[c] = true
+			columns = append(columns, c)
+		}
+		for _, row := range t.Rows {
+			for f := range row.Data {
+				if !known[f] {
+					return nil, util.NewInvalidError("field '%s' of row '%s' not listed in columns", f, row.ID)
+				}
+			}
+		}
+
+		return columns, nil
+	}
+
+	// no schema provided so use every field found, sorted by name
+	fields := make(map[string]bool)
+	for _, row := range t.Rows {
+		for f := range row.Data {
+			if f != cm.D3MIndexName {
+				fields[f] = true
+			}
+		}
+	}
+	sorted := make([]string, 0, len(fields))
+	for f := range fields {
+		sorted = append(sorted, f)
+	}
+	sort.Strings(sorted)
+
+	return append(columns, sorted...), nil
+}
+
+// formatValue writes a json value as a learning data field. Nulls are
+// written as empty fields, which D3M treats as missing values.
+func formatValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", errors.Errorf("unsupported value type %T", value)
+	}
+}
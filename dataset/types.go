@@ -15,6 +15,12 @@
 
 package dataset
 
+import (
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+)
+
 // Type is an enum for supported dataset types.
 type Type string
 
@@ -26,3 +32,14 @@ const (
 	// UnknownType is the catch all dataset type.
 	UnknownType = "Unknown"
 )
+
+// safePredictionsID returns the client supplied predictions id if it can be
+// used in paths, or a server generated one otherwise.
+func safePredictionsID(id string) string {
+	if env.IsValidID(id) {
+		return id
+	}
+	generated := env.NewID()
+	log.Warnf("replacing unsafe predictions id '%s' with '%s'", id, generated)
+	return generated
+}
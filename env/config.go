@@ -16,44 +16,173 @@
 package env
 
 import (
+	"reflect"
 	"sync"
 
 	"github.com/caarlos0/env"
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
 )
 
 var (
-	cfg  *Config
-	once sync.Once
+	cfg   *Config
+	once  sync.Once
+	mutex sync.RWMutex
 )
 
-// Config represents the application configuration state loaded from env vars.
+// Config represents the application configuration state loaded from env vars
+// and the optional config file. Settings tagged reload can be changed while
+// running by reloading the config.
 type Config struct {
-	AppPort                 string  `env:"PORT" envDefault:"8080"`
-	BatchSize               int     `env:"BATCH_SIZE" envDefault:"100"`
-	BatchSizeIncreaseFactor float64 `env:"BATCH_SIZE_INCREASE_FACTOR" envDefault:"1.2"`
-	BatchSizeDecreaseFactor float64 `env:"BATCH_SIZE_DECREASE_FACTOR" envDefault:"0.9"`
-	ClearDataset            bool    `env:"CLEAR_DATASET" envDefault:"true"`
-	D3MOutputDir            string  `env:"D3MOUTPUTDIR" envDefault:"outputs"`
-	D3MStaticDir            string  `env:"D3MSTATICDIR" envDefault:"/data/static_resources"`
-	DatasetDir              string  `env:"DATASET_DIR" envDefault:"datasets"`
-	PipelineD3M             string  `env:"PIPELINE_D3M" envDefault:"pipeline.d3m"`
-	PipelineDir             string  `env:"PIPELINE_DIR" envDefault:"pipelines"`
-	PipelineJSON            string  `env:"PIPELINE_JSON" envDefault:"pipeline.json"`
-	PredictionDir           string  `env:"PREDICTION_DIR" envDefault:"predictions"`
-	ProblemFile             string  `env:"PROBLEM_FILE" envDefault:"problemDoc.json"`
-	VerboseError            bool    `env:"VERBOSE_ERROR" envDefault:"false"`
+	AdmissionMaxConcurrent  int      `env:"ADMISSION_MAX_CONCURRENT" envDefault:"4"`
+	AdmissionQueueSize      int      `env:"ADMISSION_QUEUE_SIZE" envDefault:"32"`
+	AdmissionQueueTimeout   int      `env:"ADMISSION_QUEUE_TIMEOUT" envDefault:"120"`
+	AppPort                 string   `env:"PORT" envDefault:"8080"`
+	AuthEnabled             bool     `env:"AUTH_ENABLED" envDefault:"false"`
+	AuthJWTIssuer           string   `env:"AUTH_JWT_ISSUER" envDefault:""`
+	AuthJWTKeyFile          string   `env:"AUTH_JWT_KEY_FILE" envDefault:""`
+	AuthKeysFile            string   `env:"AUTH_KEYS_FILE" envDefault:""`
+	BatchAIMDDecreaseFactor float64  `env:"BATCH_AIMD_DECREASE_FACTOR" envDefault:"0.5" reload:"true"`
+	BatchAIMDIncrease       int      `env:"BATCH_AIMD_INCREASE" envDefault:"10" reload:"true"`
+	BatchAIMDTarget         int      `env:"BATCH_AIMD_TARGET" envDefault:"60" reload:"true"`
+	BatchMaxAttempts        int      `env:"BATCH_MAX_ATTEMPTS" envDefault:"32" reload:"true"`
+	BatchMaxFailures        int      `env:"BATCH_MAX_FAILURES" envDefault:"100" reload:"true"`
+	BatchMemoryFraction     float64  `env:"BATCH_MEMORY_FRACTION" envDefault:"0.05" reload:"true"`
+	BatchMemoryLimitMB      int      `env:"BATCH_MEMORY_LIMIT_MB" envDefault:"0" reload:"true"`
+	BatchSize               int      `env:"BATCH_SIZE" envDefault:"100" reload:"true"`
+	BatchSizeDecreaseFactor float64  `env:"BATCH_SIZE_DECREASE_FACTOR" envDefault:"0.9" reload:"true"`
+	BatchSizeIncreaseFactor float64  `env:"BATCH_SIZE_INCREASE_FACTOR" envDefault:"1.2" reload:"true"`
+	BatchSizeMax            int      `env:"BATCH_SIZE_MAX" envDefault:"10000" reload:"true"`
+	BatchSizeMin            int      `env:"BATCH_SIZE_MIN" envDefault:"1" reload:"true"`
+	BatchSizeStrategy       string   `env:"BATCH_SIZE_STRATEGY" envDefault:"throughput" reload:"true"`
+	BatchWorkers            int      `env:"BATCH_WORKERS" envDefault:"2"`
+	ClearDataset            bool     `env:"CLEAR_DATASET" envDefault:"true"`
+	ConfigFile              string   `env:"CONFIG_FILE" envDefault:""`
+	D3MOutputDir            string   `env:"D3MOUTPUTDIR" envDefault:"outputs"`
+	D3MStaticDir            string   `env:"D3MSTATICDIR" envDefault:"/data/static_resources"`
+	DatasetDir              string   `env:"DATASET_DIR" envDefault:"datasets"`
+	GRPCPort                string   `env:"GRPC_PORT" envDefault:""`
+	JobDir                  string   `env:"JOB_DIR" envDefault:"jobs"`
+	JobRetention            int      `env:"JOB_RETENTION" envDefault:"604800"`
+	PipelineCandidateD3M    string   `env:"PIPELINE_CANDIDATE_D3M" envDefault:"candidate.d3m"`
+	PipelineD3M             string   `env:"PIPELINE_D3M" envDefault:"pipeline.d3m"`
+	PipelineDir             string   `env:"PIPELINE_DIR" envDefault:"pipelines"`
+	PipelineJSON            string   `env:"PIPELINE_JSON" envDefault:"pipeline.json"`
+	PredictionCacheSize     int      `env:"PREDICTION_CACHE_SIZE" envDefault:"0"`
+	PredictionCacheTTL      int      `env:"PREDICTION_CACHE_TTL" envDefault:"3600"`
+	PredictionDir           string   `env:"PREDICTION_DIR" envDefault:"predictions"`
+	ProblemFile             string   `env:"PROBLEM_FILE" envDefault:"problemDoc.json"`
+	PromotionHoldoutRatio   float64  `env:"PROMOTION_HOLDOUT_RATIO" envDefault:"0.2"`
+	PromotionMetric         string   `env:"PROMOTION_METRIC" envDefault:""`
+	PromotionThreshold      float64  `env:"PROMOTION_THRESHOLD" envDefault:"0"`
+	RateLimitClient         float64  `env:"RATE_LIMIT_CLIENT" envDefault:"0" reload:"true"`
+	RateLimitClientBurst    int      `env:"RATE_LIMIT_CLIENT_BURST" envDefault:"10" reload:"true"`
+	RateLimitPipeline       float64  `env:"RATE_LIMIT_PIPELINE" envDefault:"0" reload:"true"`
+	RateLimitPipelineBurst  int      `env:"RATE_LIMIT_PIPELINE_BURST" envDefault:"10" reload:"true"`
+	RequestValidation       bool     `env:"REQUEST_VALIDATION" envDefault:"true"`
+	RunDir                  string   `env:"RUN_DIR" envDefault:"runs"`
+	RunnerEnv               []string `env:"RUNNER_ENV" envDefault:"PATH,HOME,LANG,LC_ALL,TMPDIR,PYTHONPATH,VIRTUAL_ENV"`
+	RunnerPython            string   `env:"RUNNER_PYTHON" envDefault:"python3"`
+	RunnerScript            string   `env:"RUNNER_SCRIPT" envDefault:"runner.py"`
+	TracingEnabled          bool     `env:"TRACING_ENABLED" envDefault:"false"`
+	TracingEndpoint         string   `env:"TRACING_ENDPOINT" envDefault:"localhost:4318"`
+	TracingInsecure         bool     `env:"TRACING_INSECURE" envDefault:"true"`
+	TracingSampleRatio      float64  `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	TracingServiceName      string   `env:"TRACING_SERVICE_NAME" envDefault:"distil-pipeline-executer"`
+	TrainingDir             string   `env:"TRAINING_DIR" envDefault:"training"`
+	VerboseError            bool     `env:"VERBOSE_ERROR" envDefault:"false" reload:"true"`
 }
 
-// LoadConfig loads the config from the environment if necessary and returns a
-// copy.
+// LoadConfig loads the config from the environment and config file if
+// necessary and returns a copy.
 func LoadConfig() (Config, error) {
 	var err error
 	once.Do(func() {
-		cfg = &Config{}
-		err = env.Parse(cfg)
+		cfg, err = readConfig()
 		if err != nil {
 			cfg = &Config{}
 		}
 	})
+
+	mutex.RLock()
+	defer mutex.RUnlock()
 	return *cfg, err
 }
+
+// ReloadConfig reads the environment and config file again and applies the
+// settings that can be reloaded, returning the names of those that changed.
+// Changes to other settings are ignored until the next restart.
+func ReloadConfig() ([]string, error) {
+	_, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	changed := make([]string, 0)
+	current := reflect.ValueOf(cfg).Elem()
+	updated := reflect.ValueOf(loaded).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		if reflect.DeepEqual(current.Field(i).Interface(), updated.Field(i).Interface()) {
+			continue
+		}
+		if field.Tag.Get("reload") != "true" {
+			log.Warnf("ignoring change to %s until the next restart", field.Tag.Get("env"))
+			continue
+		}
+		current.Field(i).Set(updated.Field(i))
+		changed = append(changed, field.Tag.Get("env"))
+	}
+
+	return changed, nil
+}
+
+// Reloaded returns a copy of the config with the settings that can be
+// reloaded set to their latest values.
+func (c *Config) Reloaded() *Config {
+	reloaded := *c
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if cfg == nil {
+		return &reloaded
+	}
+
+	value := reflect.ValueOf(&reloaded).Elem()
+	latest := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("reload") == "true" {
+			value.Field(i).Set(latest.Field(i))
+		}
+	}
+
+	return &reloaded
+}
+
+// readConfig parses the environment variables, fills in the settings not
+// set by them from the config file and validates the result.
+func readConfig() (*Config, error) {
+	config := &Config{}
+	err := env.Parse(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse environment variables")
+	}
+	if config.ConfigFile != "" {
+		err = readConfigFile(config.ConfigFile, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
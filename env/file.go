@@ -0,0 +1,133 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// readConfigFile sets the settings found in the YAML config file, skipping
+// those set by environment variables so the environment always wins. Keys
+// are the environment variable names in upper or lower case.
+func readConfigFile(filename string, config *Config) error {
+	ext := strings.ToLower(path.Ext(filename))
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return errors.Errorf("unsupported config file format '%s', only YAML is supported", ext)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read config file")
+	}
+	settings := make(map[string]interface{})
+	err = yaml.Unmarshal(data, &settings)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse config file '%s'", filename)
+	}
+
+	fields := make(map[string]int)
+	value := reflect.ValueOf(config).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		fields[value.Type().Field(i).Tag.Get("env")] = i
+	}
+
+	// sort the keys so errors are reported in a stable order
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := strings.ToUpper(key)
+		i, ok := fields[name]
+		if !ok || name == "CONFIG_FILE" {
+			return errors.Errorf("unknown setting '%s' in config file '%s'", key, filename)
+		}
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+		err = setField(value.Field(i), settings[key])
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for '%s' in config file '%s'", key, filename)
+		}
+	}
+
+	return nil
+}
+
+// setField parses the value the same way as the environment variable of the
+// field would be.
+func setField(field reflect.Value, value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		text = ""
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		text = strings.Join(items, ",")
+	case map[string]interface{}:
+		return errors.Errorf("expected a value but found a mapping")
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return errors.Errorf("'%s' is not a boolean", text)
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		i, err := strconv.Atoi(text)
+		if err != nil {
+			return errors.Errorf("'%s' is not an integer", text)
+		}
+		field.SetInt(int64(i))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return errors.Errorf("'%s' is not a number", text)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		items := make([]string, 0)
+		if text != "" {
+			items = strings.Split(text, ",")
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return errors.Errorf("unsupported setting type %s", field.Kind())
+	}
+
+	return nil
+}
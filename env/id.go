@@ -0,0 +1,69 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package env
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxIDLength = 128
+)
+
+var (
+	// ids are used as folder and file names so they must not contain path
+	// separators nor start with a dot
+	idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// IsValidID returns true if the id can be safely used as a file name.
+func IsValidID(id string) bool {
+	return len(id) <= maxIDLength && idPattern.MatchString(id)
+}
+
+// ValidateID returns an error if the id cannot be safely used as a file name.
+func ValidateID(id string) error {
+	if !IsValidID(id) {
+		return errors.Errorf("id '%s' must be at most %d letters, digits, '.', '_' or '-' and start with a letter or digit", id, maxIDLength)
+	}
+	return nil
+}
+
+// NewID generates a random id matching the id policy.
+func NewID() string {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		panic(errors.Wrap(err, "unable to generate id"))
+	}
+	return hex.EncodeToString(id)
+}
+
+// safeID returns the id if it is valid, or a stable name derived from it
+// otherwise so paths built from it always stay under their root.
+func safeID(id string) string {
+	if IsValidID(id) {
+		return id
+	}
+	hash := sha256.Sum256([]byte(id))
+	return fmt.Sprintf("invalid-%s", hex.EncodeToString(hash[:8]))
+}
@@ -0,0 +1,57 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateID(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		valid bool
+	}{
+		{"letters and digits", "pipeline1", true},
+		{"separators", "my.pipeline_v2-b", true},
+		{"max length", strings.Repeat("a", maxIDLength), true},
+		{"generated", NewID(), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", maxIDLength+1), false},
+		{"parent", "..", false},
+		{"parent prefix", "../pipeline", false},
+		{"nested parent", "pipeline/../../etc", false},
+		{"absolute", "/etc/passwd", false},
+		{"windows separator", `..\pipeline`, false},
+		{"hidden", ".pipeline", false},
+		{"leading dash", "-pipeline", false},
+		{"space", "my pipeline", false},
+		{"null byte", "pipeline\x00", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateID(test.id)
+			if (err == nil) != test.valid {
+				t.Errorf("expected id '%s' to be valid: %v, got error %v", test.id, test.valid, err)
+			}
+			if IsValidID(test.id) != test.valid {
+				t.Errorf("expected IsValidID to agree with ValidateID for '%s'", test.id)
+			}
+		})
+	}
+}
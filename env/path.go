@@ -26,9 +26,12 @@ var (
 	pipelinePath     = ""
 	pipelineJSONName = ""
 	pipelineD3MName  = ""
+	candidateD3MName = ""
 	predictionPath   = ""
 	problemPath      = ""
 	datasetPath      = ""
+	trainingDirName  = ""
+	runPath          = ""
 
 	initialized = false
 )
@@ -43,15 +46,21 @@ func Initialize(config *Config) error {
 	pipelinePath = config.PipelineDir
 	pipelineJSONName = config.PipelineJSON
 	pipelineD3MName = config.PipelineD3M
+	candidateD3MName = config.PipelineCandidateD3M
 	problemPath = config.ProblemFile
 	datasetPath = config.DatasetDir
 	predictionPath = config.PredictionDir
+	trainingDirName = config.TrainingDir
+	runPath = config.RunDir
 
 	log.Infof("using '%s' as dataset path", datasetPath)
 	log.Infof("using '%s' as prediction path", predictionPath)
+	log.Infof("using '%s' as run path", runPath)
 	log.Infof("using '%s' as pipeline path", pipelinePath)
 	log.Infof("using '%s' as pipeline json name", pipelineJSONName)
 	log.Infof("using '%s' as pipeline d3m name", pipelineD3MName)
+	log.Infof("using '%s' as candidate pipeline d3m name", candidateD3MName)
+	log.Infof("using '%s' as training data folder name", trainingDirName)
 
 	initialized = true
 
@@ -60,31 +69,67 @@ func Initialize(config *Config) error {
 
 // ResolvePipelinePath returns the path to the folder containing the pipeline info.
 func ResolvePipelinePath(pipelineID string) string {
-	return path.Join(pipelinePath, pipelineID)
+	return confine(pipelinePath, pipelineID)
 }
 
 // ResolvePipelineJSONPath returns the path to the json file representing the pipeline.
 func ResolvePipelineJSONPath(pipelineID string) string {
-	return path.Join(pipelinePath, pipelineID, pipelineJSONName)
+	return path.Join(confine(pipelinePath, pipelineID), pipelineJSONName)
 }
 
 // ResolveProblemPath returns the path to the file representing the
 // problem for the pipeline.
 func ResolveProblemPath(pipelineID string) string {
-	return path.Join(pipelinePath, pipelineID, problemPath)
+	return path.Join(confine(pipelinePath, pipelineID), problemPath)
 }
 
 // ResolvePipelineD3MPath returns the path pickled fitted pipeline.
 func ResolvePipelineD3MPath(pipelineID string) string {
-	return path.Join(pipelinePath, pipelineID, pipelineD3MName)
+	return path.Join(confine(pipelinePath, pipelineID), pipelineD3MName)
+}
+
+// ResolveCandidateD3MPath returns the path of the pickled candidate pipeline
+// staged for promotion.
+func ResolveCandidateD3MPath(pipelineID string) string {
+	return path.Join(confine(pipelinePath, pipelineID), candidateD3MName)
+}
+
+// ResolveTrainingPath returns the path of the folder accumulating the
+// training data of the pipeline.
+func ResolveTrainingPath(pipelineID string) string {
+	return path.Join(confine(pipelinePath, pipelineID), trainingDirName)
+}
+
+// ResolveRunsPath returns the path of the folder holding the runs of a pipeline.
+func ResolveRunsPath(pipelineID string) string {
+	return confine(runPath, pipelineID)
+}
+
+// ResolveRunPath returns the path of the folder holding the output of a run.
+func ResolveRunPath(pipelineID string, runID string) string {
+	return confine(runPath, pipelineID, runID)
 }
 
 // ResolveDatasetPath returns the path for a dataset folder
 func ResolveDatasetPath(datasetID string) string {
-	return path.Join(datasetPath, datasetID)
+	return confine(datasetPath, datasetID)
 }
 
 // ResolvePredictionPath returns the path for a prediction folder
 func ResolvePredictionPath(predictionID string) string {
-	return path.Join(predictionPath, predictionID)
+	return confine(predictionPath, predictionID)
+}
+
+// confine joins the ids to the root. Ids not matching the id policy are
+// replaced so the resulting path can never escape the root.
+func confine(root string, ids ...string) string {
+	elements := []string{root}
+	for _, id := range ids {
+		safe := safeID(id)
+		if safe != id {
+			log.Warnf("replacing invalid id '%s' with '%s' to keep paths under '%s'", id, safe, root)
+		}
+		elements = append(elements, safe)
+	}
+	return path.Join(elements...)
 }
@@ -0,0 +1,69 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package env
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestConfine(t *testing.T) {
+	root := "/data/pipelines"
+
+	tests := []struct {
+		name string
+		ids  []string
+		same bool
+	}{
+		{"valid", []string{"pipeline"}, true},
+		{"valid nested", []string{"pipeline", "fit-1"}, true},
+		{"empty", []string{""}, false},
+		{"too long", []string{strings.Repeat("a", maxIDLength+1)}, false},
+		{"parent", []string{".."}, false},
+		{"parent prefix", []string{"../../etc/passwd"}, false},
+		{"absolute", []string{"/etc/passwd"}, false},
+		{"nested parent", []string{"pipeline", "../../.."}, false},
+		{"nested absolute", []string{"pipeline", "/tmp"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			confined := confine(root, test.ids...)
+
+			// every id stays a single element under the root
+			parent := confined
+			for range test.ids {
+				if !IsValidID(path.Base(parent)) {
+					t.Errorf("expected '%s' to only hold valid ids", confined)
+				}
+				parent = path.Dir(parent)
+			}
+			if parent != root {
+				t.Errorf("expected '%s' to be confined under '%s'", confined, root)
+			}
+
+			expected := path.Join(append([]string{root}, test.ids...)...)
+			if (confined == expected) != test.same {
+				t.Errorf("expected the ids to be kept: %v, got '%s'", test.same, confined)
+			}
+		})
+	}
+
+	if confine(root, "../a") != confine(root, "../a") || confine(root, "../a") == confine(root, "../b") {
+		t.Errorf("expected invalid ids to be replaced by stable and distinct names")
+	}
+}
@@ -0,0 +1,114 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// batchSizeStrategies are the strategies implemented by the task package.
+var batchSizeStrategies = []string{"throughput", "aimd", "fixed"}
+
+// Validate checks the settings are usable, returning an error listing every
+// invalid one.
+func (c *Config) Validate() error {
+	problems := make([]string, 0)
+	check := func(valid bool, format string, args ...interface{}) {
+		if !valid {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.AdmissionMaxConcurrent >= 0, "ADMISSION_MAX_CONCURRENT must not be negative")
+	check(c.AdmissionQueueSize >= 0, "ADMISSION_QUEUE_SIZE must not be negative")
+	check(c.AdmissionQueueTimeout >= 0, "ADMISSION_QUEUE_TIMEOUT must not be negative")
+	check(isPort(c.AppPort), "PORT '%s' is not a valid port", c.AppPort)
+	check(c.GRPCPort == "" || isPort(c.GRPCPort), "GRPC_PORT '%s' is not a valid port", c.GRPCPort)
+	check(c.GRPCPort != c.AppPort, "GRPC_PORT must differ from PORT")
+
+	check(c.BatchSize >= 1, "BATCH_SIZE must be at least 1")
+	check(c.BatchSizeMin >= 1, "BATCH_SIZE_MIN must be at least 1")
+	check(c.BatchSizeMax >= c.BatchSizeMin, "BATCH_SIZE_MAX must be at least BATCH_SIZE_MIN")
+	check(contains(batchSizeStrategies, c.BatchSizeStrategy), "BATCH_SIZE_STRATEGY must be one of %s", strings.Join(batchSizeStrategies, ", "))
+	check(c.BatchSizeIncreaseFactor >= 1, "BATCH_SIZE_INCREASE_FACTOR must be at least 1")
+	check(c.BatchSizeDecreaseFactor > 0 && c.BatchSizeDecreaseFactor <= 1, "BATCH_SIZE_DECREASE_FACTOR must be greater than 0 and at most 1")
+	check(c.BatchAIMDIncrease >= 0, "BATCH_AIMD_INCREASE must not be negative")
+	check(c.BatchAIMDDecreaseFactor > 0 && c.BatchAIMDDecreaseFactor < 1, "BATCH_AIMD_DECREASE_FACTOR must be between 0 and 1")
+	check(c.BatchAIMDTarget > 0, "BATCH_AIMD_TARGET must be positive")
+	check(c.BatchMaxAttempts >= 1, "BATCH_MAX_ATTEMPTS must be at least 1")
+	check(c.BatchMaxFailures >= 0, "BATCH_MAX_FAILURES must not be negative")
+	check(c.BatchMemoryFraction >= 0 && c.BatchMemoryFraction <= 1, "BATCH_MEMORY_FRACTION must be between 0 and 1")
+	check(c.BatchMemoryLimitMB >= 0, "BATCH_MEMORY_LIMIT_MB must not be negative")
+	check(c.BatchWorkers >= 0, "BATCH_WORKERS must not be negative")
+
+	check(c.JobRetention >= 0, "JOB_RETENTION must not be negative")
+	check(c.PredictionCacheSize >= 0, "PREDICTION_CACHE_SIZE must not be negative")
+	check(c.PredictionCacheTTL >= 0, "PREDICTION_CACHE_TTL must not be negative")
+	check(c.PromotionHoldoutRatio > 0 && c.PromotionHoldoutRatio < 1, "PROMOTION_HOLDOUT_RATIO must be between 0 and 1")
+	check(c.RateLimitClient >= 0, "RATE_LIMIT_CLIENT must not be negative")
+	check(c.RateLimitClientBurst >= 1, "RATE_LIMIT_CLIENT_BURST must be at least 1")
+	check(c.RateLimitPipeline >= 0, "RATE_LIMIT_PIPELINE must not be negative")
+	check(c.RateLimitPipelineBurst >= 1, "RATE_LIMIT_PIPELINE_BURST must be at least 1")
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	required := map[string]string{
+		"D3MOUTPUTDIR":   c.D3MOutputDir,
+		"DATASET_DIR":    c.DatasetDir,
+		"JOB_DIR":        c.JobDir,
+		"PIPELINE_D3M":   c.PipelineD3M,
+		"PIPELINE_DIR":   c.PipelineDir,
+		"PREDICTION_DIR": c.PredictionDir,
+		"PROBLEM_FILE":   c.ProblemFile,
+		"RUN_DIR":        c.RunDir,
+		"RUNNER_PYTHON":  c.RunnerPython,
+		"RUNNER_SCRIPT":  c.RunnerScript,
+		"TRAINING_DIR":   c.TrainingDir,
+	}
+	names := make([]string, 0)
+	for name, value := range required {
+		if value == "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		problems = append(problems, fmt.Sprintf("%s must not be empty", name))
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid configuration:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+func isPort(port string) bool {
+	p, err := strconv.Atoi(port)
+	return err == nil && p > 0 && p < 65536
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
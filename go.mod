@@ -5,10 +5,20 @@ go 1.13
 require (
 	github.com/caarlos0/env v3.5.0+incompatible
 	github.com/davecgh/go-spew v1.1.1
+	github.com/golang/protobuf v1.5.2
 	github.com/pkg/errors v0.8.1
+	github.com/prometheus/client_golang v1.5.1
 	github.com/uncharted-distil/distil v0.0.0-20200214202446-d1bbf3a2728e
 	github.com/uncharted-distil/distil-compute v0.0.0-20200227185621-e7a03bf96d76
 	github.com/unchartedsoftware/plog v0.0.0-20170413154239-34d2bbd3c0a9
 	github.com/zenazn/goji v0.9.0
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.0.1
+	go.opentelemetry.io/otel/sdk v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
+	go.opentelemetry.io/proto/otlp v0.9.0
 	goji.io/v3 v3.0.0
+	golang.org/x/sys v0.0.0-20210423185535-09eb48e85fd7
+	google.golang.org/grpc v1.41.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
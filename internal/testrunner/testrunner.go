@@ -0,0 +1,129 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package testrunner fakes the pipeline runner in tests by executing the
+// test binary itself as the runner, along with a pipeline it can run.
+package testrunner
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+)
+
+const (
+	// Variable makes the test binary act as the pipeline runner.
+	Variable = "TESTRUNNER_RUNNER"
+	// TraceParentVariable holds the file the runner records the trace
+	// parent it was given to, if set.
+	TraceParentVariable = "TESTRUNNER_TRACEPARENT"
+
+	// DatasetSchema describes a dataset predicting the y column from the x
+	// column.
+	DatasetSchema = `{
+		"about": {"datasetID": "test", "datasetName": "test", "datasetSchemaVersion": "4.0.0"},
+		"dataResources": [{
+			"resID": "learningData", "resPath": "tables/learningData.csv", "resType": "table",
+			"resFormat": {"text/csv": ["csv"]}, "isCollection": false,
+			"columns": [
+				{"colIndex": 0, "colName": "d3mIndex", "colType": "integer", "role": ["index"]},
+				{"colIndex": 1, "colName": "x", "colType": "categorical", "role": ["attribute"]},
+				{"colIndex": 2, "colName": "y", "colType": "categorical", "role": ["suggestedTarget"]}
+			]
+		}]
+	}`
+	// Problem is the classification problem of the dataset.
+	Problem = `{
+		"about": {"problemID": "test", "taskKeywords": ["classification"]},
+		"inputs": {
+			"data": [{"datasetID": "test", "targets": [{"resID": "learningData", "colIndex": 2, "colName": "y"}]}],
+			"performanceMetrics": [{"metric": "accuracy"}]
+		}
+	}`
+	// Pipeline is a pipeline exposing a single predictions output.
+	Pipeline = `{"id": "test", "outputs": [{"name": "predictions", "data": "steps.0.produce"}]}`
+)
+
+// RunIfRunner fakes the runner and exits if the test binary was executed as
+// the runner. It is meant to be called first thing in TestMain.
+func RunIfRunner() {
+	if os.Getenv(Variable) != "" {
+		os.Exit(run(os.Args[2:]))
+	}
+}
+
+// Configure makes the test binary the runner used by the config.
+func Configure(config *env.Config) error {
+	runner, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	config.RunnerPython = runner
+	config.RunnerEnv = []string{Variable, TraceParentVariable}
+
+	return os.Setenv(Variable, "1")
+}
+
+// run fakes the runner script, storing a fitted pipeline when fitting and
+// predicting the x column of every row when producing.
+func run(args []string) int {
+	if filename := os.Getenv(TraceParentVariable); filename != "" {
+		err := ioutil.WriteFile(filename, []byte(os.Getenv("TRACEPARENT")), 0644)
+		if err != nil {
+			return exitCode(err)
+		}
+	}
+
+	flags := make(map[string]string)
+	for i := 0; i+1 < len(args); i++ {
+		if args[i][0] == '-' {
+			flags[args[i]] = args[i+1]
+		}
+	}
+
+	if flags["-s"] != "" {
+		return exitCode(ioutil.WriteFile(flags["-s"], []byte("fitted"), 0644))
+	}
+
+	input, err := os.Open(path.Join(path.Dir(flags["-t"]), "tables", "learningData.csv"))
+	if err != nil {
+		return exitCode(err)
+	}
+	defer input.Close()
+	rows, err := csv.NewReader(input).ReadAll()
+	if err != nil {
+		return exitCode(err)
+	}
+	predictions := [][]string{{"d3mIndex", "y"}}
+	for _, row := range rows[1:] {
+		predictions = append(predictions, []string{row[0], row[1]})
+	}
+	output := &bytes.Buffer{}
+	writer := csv.NewWriter(output)
+	writer.WriteAll(predictions)
+	return exitCode(ioutil.WriteFile(flags["-o"], output.Bytes(), 0644))
+}
+
+func exitCode(err error) int {
+	if err != nil {
+		os.Stderr.WriteString(err.Error())
+		return 1
+	}
+	return 0
+}
@@ -16,20 +16,24 @@
 package main
 
 import (
-	"net/http"
+	"flag"
 	"os"
+	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
-	"github.com/davecgh/go-spew/spew"
+	"github.com/pkg/errors"
 	log "github.com/unchartedsoftware/plog"
 	"github.com/zenazn/goji/graceful"
-	goji "goji.io/v3"
-	"goji.io/v3/pat"
 
+	"github.com/uncharted-distil/distil-pipeline-executer/admission"
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
 	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
 	"github.com/uncharted-distil/distil-pipeline-executer/routes"
-	"github.com/uncharted-distil/distil-pipeline-executer/util"
-	"github.com/uncharted-distil/distil/api/middleware"
+	"github.com/uncharted-distil/distil-pipeline-executer/rpc"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
 )
 
 var (
@@ -39,59 +43,158 @@ var (
 	datasetDocPath = ""
 )
 
-func registerRoute(mux *goji.Mux, pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	log.Infof("Registering GET route %s", pattern)
-	mux.HandleFunc(pat.Get(pattern), handler)
-}
-
-func registerRoutePost(mux *goji.Mux, pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	log.Infof("Registering POST route %s", pattern)
-	mux.HandleFunc(pat.Post(pattern), handler)
-}
-
 func main() {
-	log.Infof("version: %s built: %s", version, timestamp)
+	// the server is started if no command is given
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
 
-	// load config from env
-	config, err := env.LoadConfig()
+	err := cmd.run(args)
 	if err != nil {
 		log.Errorf("%+v", err)
 		os.Exit(1)
 	}
-	log.Infof("%+v", spew.Sdump(config))
-	env.Initialize(&config)
-	util.SetConfig(&config)
+}
 
-	// register routes
-	mux := goji.NewMux()
-	mux.Use(middleware.Log)
-	mux.Use(middleware.Gzip)
+// serveCommand runs the http server until it is shut down.
+func serveCommand(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.Parse(args)
 
-	routes.SetVerboseError(config.VerboseError)
+	config, shutdownTracing, err := setup(true)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+
+	// load the API keys and JWT key
+	authenticator, err := auth.NewAuthenticator(config)
+	if err != nil {
+		return err
+	}
+	routes.SetAuthenticator(authenticator)
 
-	// GET
-	registerRoute(mux, "/distil/pipelines", routes.PipelinesHandler(config))
-	registerRoute(mux, "/distil/config", routes.ConfigHandler(config, version, timestamp))
+	err = metrics.RegisterDiskUsage(map[string]string{
+		"datasets":    config.DatasetDir,
+		"predictions": config.PredictionDir,
+	})
+	if err != nil {
+		return err
+	}
 
-	// POST
-	registerRoutePost(mux, "/distil/fit/:pipeline-id", routes.FitHandler(&config))
-	registerRoutePost(mux, "/distil/produce/:pipeline-id", routes.ProduceHandler(&config))
-	registerRoutePost(mux, "/distil/upload/:pipeline-id", routes.UploadHandler(config.PipelineDir))
+	routes.SetVerboseError(config.VerboseError)
+	controller := admission.NewController(config.AdmissionMaxConcurrent, config.AdmissionQueueSize, time.Duration(config.AdmissionQueueTimeout)*time.Second)
+	limits := admission.NewLimits(
+		admission.NewRateLimiter(config.RateLimitClient, config.RateLimitClientBurst),
+		admission.NewRateLimiter(config.RateLimitPipeline, config.RateLimitPipelineBurst))
+	routes.SetAdmission(controller, limits)
+
+	// rows to produce are shared by all requests
+	queue := task.NewQueue(config.BatchWorkers)
+	task.SetPredictionCache(task.NewPredictionCache(config.PredictionCacheSize, time.Duration(config.PredictionCacheTTL)*time.Second))
+
+	// fail the jobs cut short by the last shutdown
+	jobs, err := task.NewJobStore(config.JobDir)
+	if err != nil {
+		return err
+	}
+	err = jobs.Recover()
+	if err != nil {
+		return err
+	}
+	if config.JobRetention > 0 {
+		go pruneJobs(jobs, config.RunDir, time.Duration(config.JobRetention)*time.Second)
+	}
 
-	// static
-	registerRoute(mux, "/*", routes.FileHandler("./dist"))
+	// register routes
+	mux := routes.NewMux(config, routes.Routes(config, queue, jobs, version, timestamp))
 
 	// catch kill signals for graceful shutdown
 	graceful.AddSignal(syscall.SIGINT, syscall.SIGTERM)
 
+	// apply the settings that can be changed while running on SIGHUP
+	go reloadOnHangup(limits)
+
+	// serve the gRPC API alongside if enabled, sharing the queue and jobs
+	if config.GRPCPort != "" {
+		grpcServer, err := rpc.Listen(config.GRPCPort, rpc.NewServer(config, queue, jobs, controller, limits, authenticator))
+		if err != nil {
+			return err
+		}
+		graceful.PreHook(grpcServer.GracefulStop)
+		log.Infof("Listening for gRPC calls on port %s", config.GRPCPort)
+	}
+
 	// kick off the server listen loop
 	log.Infof("Listening on port %s", config.AppPort)
 	err = graceful.ListenAndServe(":"+config.AppPort, mux)
 	if err != nil {
-		log.Errorf("%+v", err)
-		os.Exit(1)
+		return err
 	}
 
 	// wait until server gracefully exits
 	graceful.Wait()
+
+	return nil
+}
+
+// pruneJobs removes the jobs and the runs older than the retention on
+// startup and every hour after that.
+func pruneJobs(jobs *task.JobStore, runDir string, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		removed, err := jobs.Prune(retention)
+		if err != nil {
+			log.Warnf("unable to prune jobs: %+v", err)
+		} else if removed > 0 {
+			log.Infof("pruned %d jobs older than %s", removed, retention)
+		}
+		removed, err = task.PruneRuns(runDir, retention)
+		if err != nil {
+			log.Warnf("unable to prune runs: %+v", err)
+		} else if removed > 0 {
+			log.Infof("pruned %d runs older than %s", removed, retention)
+		}
+		<-ticker.C
+	}
+}
+
+// reloadOnHangup reloads the config whenever the process receives SIGHUP.
+// Batch sizing is read from the reloaded config by every produce call while
+// error verbosity and rate limits are pushed to the routes and limits shared
+// by both transports.
+func reloadOnHangup(limits *admission.Limits) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		changed, err := env.ReloadConfig()
+		if err != nil {
+			log.Errorf("%+v", errors.Wrap(err, "unable to reload config, keeping the current one"))
+			continue
+		}
+		if len(changed) == 0 {
+			log.Infof("reloaded config without changes")
+			continue
+		}
+
+		config, _ := env.LoadConfig()
+		routes.SetVerboseError(config.VerboseError)
+		for _, name := range changed {
+			// replacing the limiters resets their buckets so only do it if needed
+			if strings.HasPrefix(name, "RATE_LIMIT_") {
+				limits.Set(
+					admission.NewRateLimiter(config.RateLimitClient, config.RateLimitClientBurst),
+					admission.NewRateLimiter(config.RateLimitPipeline, config.RateLimitPipelineBurst))
+				break
+			}
+		}
+		log.Infof("reloaded config, changed %s", strings.Join(changed, ", "))
+	}
 }
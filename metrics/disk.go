@@ -0,0 +1,78 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/unchartedsoftware/plog"
+)
+
+var (
+	diskUsageDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "disk_usage_bytes"),
+		"Size of the files found in the working directories.",
+		[]string{"directory"}, nil)
+)
+
+// diskCollector computes the size of the working directories when scraped.
+type diskCollector struct {
+	directories map[string]string
+}
+
+// RegisterDiskUsage exposes the disk usage of the named directories.
+func RegisterDiskUsage(directories map[string]string) error {
+	return prometheus.Register(&diskCollector{
+		directories: directories,
+	})
+}
+
+// Describe sends the descriptor of the disk usage metric.
+func (c *diskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- diskUsageDesc
+}
+
+// Collect walks the directories to compute their current size.
+func (c *diskCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, directory := range c.directories {
+		size, err := directorySize(directory)
+		if err != nil {
+			log.Warnf("unable to compute disk usage of '%s': %v", directory, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(diskUsageDesc, prometheus.GaugeValue, float64(size), name)
+	}
+}
+
+func directorySize(directory string) (int64, error) {
+	var size int64
+	err := filepath.Walk(directory, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			// files can be removed by other requests while walking
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			size = size + info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
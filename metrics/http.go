@@ -0,0 +1,91 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"goji.io/v3/middleware"
+	"goji.io/v3/pat"
+	"goji.io/v3/pattern"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+)
+
+const (
+	// otherPipeline labels the requests to invalid or unknown pipelines.
+	otherPipeline = "other"
+)
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Handler returns the handler exposing the metrics to prometheus. Compression
+// is left to the gzip middleware.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+		DisableCompression: true,
+	})
+}
+
+// Middleware records the count and latency of requests by route and
+// pipeline. It must be used after routing so the route pattern is known.
+func Middleware(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if p, ok := middleware.Pattern(r.Context()).(*pat.Pattern); ok {
+			route = p.String()
+		}
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r)
+
+		// the pipeline is labelled once handled so uploads are counted
+		pipelineID, _ := r.Context().Value(pattern.Variable("pipeline-id")).(string)
+		if pipelineID != "" {
+			pipelineID = pipelineLabel(pipelineID)
+		}
+		requestDuration.WithLabelValues(route, pipelineID).Observe(time.Since(start).Seconds())
+		requestCount.WithLabelValues(route, pipelineID, strconv.Itoa(sw.status)).Inc()
+	}
+	return http.HandlerFunc(fn)
+}
+
+// pipelineLabel returns the pipeline id if it names an uploaded pipeline and
+// otherPipeline if not, so arbitrary ids sent by clients do not each create
+// new series.
+func pipelineLabel(pipelineID string) string {
+	if !env.IsValidID(pipelineID) {
+		return otherPipeline
+	}
+	if _, err := os.Stat(env.ResolvePipelineJSONPath(pipelineID)); err != nil {
+		return otherPipeline
+	}
+	return pipelineID
+}
@@ -0,0 +1,160 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "distil_executer"
+)
+
+var (
+	requestCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Count of HTTP requests by route, pipeline and status code.",
+	}, []string{"route", "pipeline", "code"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route and pipeline.",
+		Buckets:   []float64{0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"route", "pipeline"})
+	runnerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "runner_duration_seconds",
+		Help:      "Duration of the runner subprocess by command and pipeline.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600},
+	}, []string{"command", "pipeline"})
+	runnerFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runner_failures_total",
+		Help:      "Count of runner subprocesses that exited with an error.",
+	}, []string{"command", "pipeline"})
+	batchSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "produce_batch_size",
+		Help:      "Size of the batches used to produce predictions.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"pipeline"})
+	rowsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rows_processed_total",
+		Help:      "Count of rows processed by operation and pipeline.",
+	}, []string{"operation", "pipeline"})
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Count of rows waiting in produce queues.",
+	})
+	queueDatasets = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_datasets",
+		Help:      "Count of datasets registered in produce queues.",
+	})
+	queueActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_active_batches",
+		Help:      "Count of batches taken from produce queues and still running.",
+	})
+	predictionCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prediction_cache_lookups_total",
+		Help:      "Count of rows looked up in the prediction cache by pipeline and result.",
+	}, []string{"pipeline", "result"})
+	admissionActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "admission_active",
+		Help:      "Count of admitted requests currently being served.",
+	})
+	admissionWaiting = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "admission_waiting",
+		Help:      "Count of requests waiting for admission by priority.",
+	}, []string{"priority"})
+	admissionRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "admission_rejected_total",
+		Help:      "Count of requests rejected by admission control by reason.",
+	}, []string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(requestCount, requestDuration, runnerDuration,
+		runnerFailures, batchSize, rowsProcessed, queueDepth, queueDatasets, queueActive, admissionActive,
+		admissionWaiting, admissionRejected, predictionCacheLookups)
+}
+
+// ObserveRunner records the duration and outcome of a runner subprocess.
+func ObserveRunner(command string, pipelineID string, duration time.Duration, failed bool) {
+	runnerDuration.WithLabelValues(command, pipelineID).Observe(duration.Seconds())
+	if failed {
+		runnerFailures.WithLabelValues(command, pipelineID).Inc()
+	}
+}
+
+// ObserveBatchSize records the size of a produce batch.
+func ObserveBatchSize(pipelineID string, size int) {
+	batchSize.WithLabelValues(pipelineID).Observe(float64(size))
+}
+
+// AddRowsProcessed adds to the count of rows processed by an operation.
+func AddRowsProcessed(operation string, pipelineID string, count int) {
+	rowsProcessed.WithLabelValues(operation, pipelineID).Add(float64(count))
+}
+
+// AddQueueDepth adjusts the count of rows waiting in queues.
+func AddQueueDepth(delta int) {
+	queueDepth.Add(float64(delta))
+}
+
+// AddQueueDatasets adjusts the count of datasets registered in queues.
+func AddQueueDatasets(delta int) {
+	queueDatasets.Add(float64(delta))
+}
+
+// AddQueueActive adjusts the count of batches taken from queues and still
+// running.
+func AddQueueActive(delta int) {
+	queueActive.Add(float64(delta))
+}
+
+// AddPredictionCacheLookups counts the rows found and missing in the
+// prediction cache.
+func AddPredictionCacheLookups(pipelineID string, hits int, misses int) {
+	label := pipelineLabel(pipelineID)
+	predictionCacheLookups.WithLabelValues(label, "hit").Add(float64(hits))
+	predictionCacheLookups.WithLabelValues(label, "miss").Add(float64(misses))
+}
+
+// AddAdmissionActive adjusts the count of admitted requests being served.
+func AddAdmissionActive(delta int) {
+	admissionActive.Add(float64(delta))
+}
+
+// AddAdmissionWaiting adjusts the count of requests waiting for admission.
+func AddAdmissionWaiting(priority string, delta int) {
+	admissionWaiting.WithLabelValues(priority).Add(float64(delta))
+}
+
+// AddAdmissionRejected counts a request rejected by admission control.
+func AddAdmissionRejected(reason string) {
+	admissionRejected.WithLabelValues(reason).Inc()
+}
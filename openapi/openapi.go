@@ -0,0 +1,198 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	refPrefix = "#/components/"
+)
+
+// Document is the part of an OpenAPI document needed to validate requests.
+type Document struct {
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas       map[string]*Schema      `json:"schemas"`
+		Parameters    map[string]*Parameter   `json:"parameters"`
+		RequestBodies map[string]*RequestBody `json:"requestBodies"`
+	} `json:"components"`
+
+	raw []byte
+}
+
+// Operation is a single method of a path.
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Parameters  []*Parameter `json:"parameters"`
+	RequestBody *RequestBody `json:"requestBody"`
+}
+
+// Parameter is a path or query parameter of an operation.
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody lists the accepted content types of a request body.
+type RequestBody struct {
+	Ref      string                `json:"$ref"`
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// MediaType holds the schema of a request body content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+var (
+	spec *Document
+)
+
+func init() {
+	var err error
+	spec, err = Parse([]byte(specJSON))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Spec returns the OpenAPI document of the service.
+func Spec() *Document {
+	return spec
+}
+
+// Parse reads an OpenAPI document, resolving the references to its
+// components.
+func Parse(data []byte) (*Document, error) {
+	doc := &Document{raw: data}
+	err := json.Unmarshal(data, doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse OpenAPI document")
+	}
+
+	for _, s := range doc.Components.Schemas {
+		err = doc.resolveSchema(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for p, operations := range doc.Paths {
+		for method, op := range operations {
+			err = doc.resolveOperation(op)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to resolve %s %s", method, p)
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+// JSON returns the document as it was read.
+func (d *Document) JSON() []byte {
+	return d.raw
+}
+
+// Operation returns the operation matching the method and path, using the
+// OpenAPI path template syntax, or nil if the document does not describe it.
+func (d *Document) Operation(method string, path string) *Operation {
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+func (d *Document) resolveOperation(op *Operation) error {
+	for i, p := range op.Parameters {
+		if p.Ref != "" {
+			resolved, ok := d.Components.Parameters[strings.TrimPrefix(p.Ref, refPrefix+"parameters/")]
+			if !ok {
+				return errors.Errorf("unknown parameter '%s'", p.Ref)
+			}
+			op.Parameters[i] = resolved
+			p = resolved
+		}
+		err := d.resolveSchema(p.Schema)
+		if err != nil {
+			return err
+		}
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Ref != "" {
+		resolved, ok := d.Components.RequestBodies[strings.TrimPrefix(op.RequestBody.Ref, refPrefix+"requestBodies/")]
+		if !ok {
+			return errors.Errorf("unknown request body '%s'", op.RequestBody.Ref)
+		}
+		op.RequestBody = resolved
+	}
+	if op.RequestBody != nil {
+		for _, m := range op.RequestBody.Content {
+			err := d.resolveSchema(m.Schema)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveSchema links the schema references to the component schemas. The
+// components are shared so recursive schemas are supported.
+func (d *Document) resolveSchema(s *Schema) error {
+	if s == nil || s.resolved {
+		return nil
+	}
+	s.resolved = true
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "unable to compile pattern '%s'", s.Pattern)
+		}
+		s.pattern = pattern
+	}
+
+	if s.Ref != "" {
+		target, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix+"schemas/")]
+		if !ok {
+			return errors.Errorf("unknown schema '%s'", s.Ref)
+		}
+		s.target = target
+		return d.resolveSchema(target)
+	}
+
+	children := []*Schema{s.Items, s.AdditionalProperties}
+	children = append(children, s.OneOf...)
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	for _, c := range children {
+		err := d.resolveSchema(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	jsonContentType = "application/json"
+)
+
+// ValidateParameters checks the path and query parameters of a request
+// against the operation. Path parameters are read using the lookup function.
+func (o *Operation) ValidateParameters(query url.Values, pathParam func(name string) (string, bool)) error {
+	violations := make([]string, 0)
+	for _, p := range o.Parameters {
+		var value string
+		var ok bool
+		switch p.In {
+		case "path":
+			value, ok = pathParam(p.Name)
+		case "query":
+			_, ok = query[p.Name]
+			value = query.Get(p.Name)
+		default:
+			continue
+		}
+
+		if !ok || value == "" {
+			if p.Required {
+				violations = append(violations, p.Name+" is required")
+			}
+			continue
+		}
+		if p.Schema != nil {
+			violations = append(violations, p.Schema.Validate(p.Name, parseParameter(p.Schema, value))...)
+		}
+	}
+
+	if len(violations) > 0 {
+		return util.WithKindDetails(errors.Errorf("invalid request parameters: %s", violations[0]), util.KindBadRequest, violations)
+	}
+	return nil
+}
+
+// RequiresBody returns true if the operation validates request bodies of the
+// content type, which are then expected to be json.
+func (o *Operation) RequiresBody(contentType string) bool {
+	return o.mediaType(contentType) != nil
+}
+
+// ValidateBody checks the request body against the schema of the operation.
+// Bodies of content types other than json, such as multipart uploads, are
+// left to the handler.
+func (o *Operation) ValidateBody(contentType string, body []byte) error {
+	media := o.mediaType(contentType)
+	if media == nil {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if o.RequestBody.Required {
+			return util.NewInvalidError("request body is required")
+		}
+		return nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	if err != nil {
+		return util.WithKind(errors.Wrap(err, "unable to parse request body"), util.KindBadRequest)
+	}
+
+	if media.Schema != nil {
+		violations := media.Schema.Validate("body", value)
+		if len(violations) > 0 {
+			return util.WithKindDetails(errors.Errorf("invalid request body: %s", violations[0]), util.KindInvalid, violations)
+		}
+	}
+	return nil
+}
+
+// mediaType returns the json media type of the operation unless the request
+// is sent as another content type the operation accepts. Bodies are parsed
+// as json by the handlers whatever their content type.
+func (o *Operation) mediaType(contentType string) *MediaType {
+	if o.RequestBody == nil {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != jsonContentType {
+		if _, ok := o.RequestBody.Content[mediaType]; ok {
+			return nil
+		}
+	}
+	return o.RequestBody.Content[jsonContentType]
+}
+
+// parseParameter converts the raw parameter to the type of its schema so it
+// can be validated, leaving it as a string if it cannot be converted.
+func parseParameter(schema *Schema, value string) interface{} {
+	if schema.target != nil {
+		schema = schema.target
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
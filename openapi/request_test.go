@@ -0,0 +1,83 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package openapi
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+func TestValidateParameters(t *testing.T) {
+	operation := parseTestSpec(t).Operation("POST", "/items/{item-id}")
+	if operation == nil {
+		t.Fatalf("operation not found")
+	}
+
+	tests := []struct {
+		name       string
+		itemID     string
+		query      string
+		violations []string
+	}{
+		{"valid", "abc", "mode=fast&limit=5&dry=true", nil},
+		{"optional omitted", "abc", "mode=slow", nil},
+		{"missing path", "", "mode=fast", []string{"item-id is required"}},
+		{"invalid path", "ABC", "mode=fast", []string{"item-id must match '^[a-z]+$'"}},
+		{"missing query", "abc", "", []string{"mode is required"}},
+		{"empty query", "abc", "mode=", []string{"mode is required"}},
+		{"enum", "abc", "mode=medium", []string{"mode must be one of 'fast', 'slow'"}},
+		{"not an integer", "abc", "mode=fast&limit=x", []string{"limit must be an integer"}},
+		{"fraction", "abc", "mode=fast&limit=1.5", []string{"limit must be an integer"}},
+		{"out of range", "abc", "mode=fast&limit=11", []string{"limit must be at most 10"}},
+		{"not a boolean", "abc", "mode=fast&dry=maybe", []string{"dry must be a boolean"}},
+		{"several", "", "limit=0", []string{"item-id is required", "limit must be at least 1", "mode is required"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := url.ParseQuery(test.query)
+			if err != nil {
+				t.Fatalf("unable to parse query: %v", err)
+			}
+			err = operation.ValidateParameters(query, func(name string) (string, bool) {
+				if name != "item-id" || test.itemID == "" {
+					return "", false
+				}
+				return test.itemID, true
+			})
+
+			if test.violations == nil {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected %q, got no error", test.violations)
+			}
+			kind, details := util.GetErrorKind(err)
+			if kind != util.KindBadRequest {
+				t.Errorf("expected a bad request, got %v", kind)
+			}
+			if !reflect.DeepEqual(details, test.violations) {
+				t.Errorf("expected %q, got %q", test.violations, details)
+			}
+		})
+	}
+}
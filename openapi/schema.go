@@ -0,0 +1,242 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	maxViolations = 20
+)
+
+// Schema is the subset of the OpenAPI schema object used to describe the
+// requests of the service. Additional properties are only supported as a
+// schema, not as a boolean.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	OneOf                []*Schema          `json:"oneOf"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+
+	resolved bool
+	target   *Schema
+	pattern  *regexp.Regexp
+}
+
+// Validate checks the value, decoded from json using numbers, against the
+// schema, returning every violation found up to a limit. The name is used
+// to locate the violations.
+func (s *Schema) Validate(name string, value interface{}) []string {
+	violations := make([]string, 0)
+	s.validate(name, value, &violations)
+	if len(violations) > maxViolations {
+		violations = append(violations[:maxViolations], fmt.Sprintf("%d more violations", len(violations)-maxViolations))
+	}
+	return violations
+}
+
+func (s *Schema) validate(name string, value interface{}, violations *[]string) {
+	if s.target != nil {
+		s.target.validate(name, value, violations)
+		return
+	}
+
+	if value == nil {
+		// a schema without a type accepts anything
+		if !s.Nullable && (s.Type != "" || len(s.OneOf) > 0) {
+			*violations = append(*violations, fmt.Sprintf("%s must not be null", name))
+		}
+		return
+	}
+
+	if len(s.OneOf) > 0 {
+		s.validateOneOf(name, value, violations)
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			*violations = append(*violations, fmt.Sprintf("%s must be an object", name))
+			return
+		}
+		s.validateObject(name, object, violations)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			*violations = append(*violations, fmt.Sprintf("%s must be an array", name))
+			return
+		}
+		if s.Items != nil {
+			for i, v := range array {
+				s.Items.validate(fmt.Sprintf("%s[%d]", name, i), v, violations)
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			*violations = append(*violations, fmt.Sprintf("%s must be a string", name))
+			return
+		}
+		if s.MaxLength != nil && len(str) > *s.MaxLength {
+			*violations = append(*violations, fmt.Sprintf("%s must be at most %d characters", name, *s.MaxLength))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			*violations = append(*violations, fmt.Sprintf("%s must match '%s'", name, s.Pattern))
+		}
+	case "number", "integer":
+		number, ok := value.(json.Number)
+		if !ok && s.Type == "integer" {
+			*violations = append(*violations, fmt.Sprintf("%s must be an integer", name))
+			return
+		} else if !ok {
+			*violations = append(*violations, fmt.Sprintf("%s must be a number", name))
+			return
+		}
+		s.validateNumber(name, number, violations)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*violations = append(*violations, fmt.Sprintf("%s must be a boolean", name))
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		*violations = append(*violations, fmt.Sprintf("%s must be one of %s", name, s.enumNames()))
+	}
+}
+
+func (s *Schema) validateOneOf(name string, value interface{}, violations *[]string) {
+	matches := 0
+	var closest []string
+	for _, o := range s.OneOf {
+		found := make([]string, 0)
+		o.validate(name, value, &found)
+		if len(found) == 0 {
+			matches++
+		} else if closest == nil || len(found) < len(closest) {
+			closest = found
+		}
+	}
+
+	switch {
+	case matches == 0 && len(closest) > 0 && !s.allTyped():
+		// report why the alternative that came closest did not match
+		*violations = append(*violations, closest...)
+	case matches != 1:
+		types := make([]string, len(s.OneOf))
+		for i, o := range s.OneOf {
+			types[i] = o.describe()
+		}
+		*violations = append(*violations, fmt.Sprintf("%s must match exactly one of %s", name, strings.Join(types, ", ")))
+	}
+}
+
+// allTyped returns true if the alternatives are plain types, in which case
+// listing them explains a mismatch better than the closest alternative.
+func (s *Schema) allTyped() bool {
+	for _, o := range s.OneOf {
+		if o.Ref != "" || o.Type == "object" || o.Type == "array" {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Schema) validateObject(name string, object map[string]interface{}, violations *[]string) {
+	for _, r := range s.Required {
+		if _, ok := object[r]; !ok {
+			*violations = append(*violations, fmt.Sprintf("%s.%s is required", name, r))
+		}
+	}
+
+	// sort the fields to report violations in a stable order
+	fields := make([]string, 0, len(object))
+	for f := range object {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		if property, ok := s.Properties[f]; ok {
+			property.validate(name+"."+f, object[f], violations)
+		} else if s.AdditionalProperties != nil {
+			s.AdditionalProperties.validate(name+"."+f, object[f], violations)
+		}
+	}
+}
+
+func (s *Schema) validateNumber(name string, number json.Number, violations *[]string) {
+	if s.Type == "integer" {
+		if _, err := number.Int64(); err != nil {
+			*violations = append(*violations, fmt.Sprintf("%s must be an integer", name))
+			return
+		}
+	}
+	f, err := number.Float64()
+	if err != nil {
+		*violations = append(*violations, fmt.Sprintf("%s must be a number", name))
+		return
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		*violations = append(*violations, fmt.Sprintf("%s must be at least %v", name, *s.Minimum))
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		*violations = append(*violations, fmt.Sprintf("%s must be at most %v", name, *s.Maximum))
+	}
+}
+
+func (s *Schema) inEnum(value interface{}) bool {
+	for _, e := range s.Enum {
+		if fmt.Sprintf("%v", e) == fmt.Sprintf("%v", value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) enumNames() string {
+	names := make([]string, len(s.Enum))
+	for i, e := range s.Enum {
+		names[i] = fmt.Sprintf("'%v'", e)
+	}
+	return strings.Join(names, ", ")
+}
+
+// describe names the schema in violation messages.
+func (s *Schema) describe() string {
+	if s.Ref != "" {
+		return s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+	}
+	if s.Type != "" {
+		return s.Type
+	}
+	return "any"
+}
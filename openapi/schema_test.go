@@ -0,0 +1,137 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testSpec = `{
+	"paths": {
+		"/items/{item-id}": {
+			"post": {
+				"parameters": [
+					{"$ref": "#/components/parameters/ItemID"},
+					{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 10}},
+					{"name": "mode", "in": "query", "required": true, "schema": {"type": "string", "enum": ["fast", "slow"]}},
+					{"name": "dry", "in": "query", "schema": {"type": "boolean"}}
+				]
+			}
+		}
+	},
+	"components": {
+		"parameters": {
+			"ItemID": {"name": "item-id", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[a-z]+$"}}
+		},
+		"schemas": {
+			"Item": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string", "maxLength": 5},
+					"count": {"type": "integer", "minimum": 0},
+					"ratio": {"type": "number", "maximum": 1},
+					"tags": {"type": "array", "items": {"type": "string"}},
+					"kind": {"type": "string", "enum": ["a", "b"]},
+					"note": {"type": "string", "nullable": true},
+					"value": {"oneOf": [{"type": "string"}, {"type": "number"}]},
+					"child": {"oneOf": [{"$ref": "#/components/schemas/Item"}, {"type": "string"}]},
+					"extra": {"type": "object", "additionalProperties": {"type": "boolean"}},
+					"any": {}
+				}
+			}
+		}
+	}
+}`
+
+func parseTestSpec(t *testing.T) *Document {
+	doc, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("unable to parse test spec: %v", err)
+	}
+	return doc
+}
+
+func decode(t *testing.T, data string) interface{} {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	if err != nil {
+		t.Fatalf("unable to decode '%s': %v", data, err)
+	}
+	return value
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := parseTestSpec(t).Components.Schemas["Item"]
+
+	tests := []struct {
+		name       string
+		value      string
+		violations []string
+	}{
+		{"valid", `{"name": "abc", "count": 2, "ratio": 0.5, "tags": ["x"], "kind": "a", "note": null, "any": [1]}`, []string{}},
+		{"not an object", `"abc"`, []string{"body must be an object"}},
+		{"missing required", `{}`, []string{"body.name is required"}},
+		{"null", `null`, []string{"body must not be null"}},
+		{"null property", `{"name": null}`, []string{"body.name must not be null"}},
+		{"too long", `{"name": "abcdef"}`, []string{"body.name must be at most 5 characters"}},
+		{"not a string", `{"name": 1}`, []string{"body.name must be a string"}},
+		{"not an integer", `{"name": "a", "count": 1.5}`, []string{"body.count must be an integer"}},
+		{"integer as string", `{"name": "a", "count": "1"}`, []string{"body.count must be an integer"}},
+		{"below minimum", `{"name": "a", "count": -1}`, []string{"body.count must be at least 0"}},
+		{"above maximum", `{"name": "a", "ratio": 2}`, []string{"body.ratio must be at most 1"}},
+		{"not a number", `{"name": "a", "ratio": true}`, []string{"body.ratio must be a number"}},
+		{"array items", `{"name": "a", "tags": ["x", 1, 2]}`, []string{"body.tags[1] must be a string", "body.tags[2] must be a string"}},
+		{"not an array", `{"name": "a", "tags": "x"}`, []string{"body.tags must be an array"}},
+		{"enum", `{"name": "a", "kind": "c"}`, []string{"body.kind must be one of 'a', 'b'"}},
+		{"one of typed", `{"name": "a", "value": true}`, []string{"body.value must match exactly one of string, number"}},
+		{"one of closest", `{"name": "a", "child": {"name": 1}}`, []string{"body.child.name must be a string"}},
+		{"recursive", `{"name": "a", "child": {"name": "b", "child": "c"}}`, []string{}},
+		{"additional properties", `{"name": "a", "extra": {"x": true, "y": 1}}`, []string{"body.extra.y must be a boolean"}},
+		{"unknown property", `{"name": "a", "other": 1}`, []string{}},
+		{"stable order", `{"name": 1, "count": "x", "kind": 1}`, []string{"body.count must be an integer", "body.kind must be a string", "body.name must be a string"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations := schema.Validate("body", decode(t, test.value))
+			if !reflect.DeepEqual(violations, test.violations) {
+				t.Errorf("expected %q, got %q", test.violations, violations)
+			}
+		})
+	}
+}
+
+func TestSchemaValidateLimit(t *testing.T) {
+	schema := parseTestSpec(t).Components.Schemas["Item"]
+
+	tags := make([]interface{}, maxViolations+5)
+	for i := range tags {
+		tags[i] = json.Number("1")
+	}
+	violations := schema.Validate("body", map[string]interface{}{"name": "a", "tags": tags})
+	if len(violations) != maxViolations+1 {
+		t.Fatalf("expected %d violations, got %d", maxViolations+1, len(violations))
+	}
+	if violations[maxViolations] != "5 more violations" {
+		t.Errorf("expected the remaining violations to be counted, got '%s'", violations[maxViolations])
+	}
+}
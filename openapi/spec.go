@@ -0,0 +1,531 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package openapi
+
+// specJSON is the OpenAPI document describing every route of the service.
+// Request validation relies on the parameters and request bodies it lists.
+const specJSON = `
+{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "distil-pipeline-executer",
+    "description": "Fits D3M pipelines and produces predictions from them.",
+    "version": "1"
+  },
+  "components": {
+    "securitySchemes": {
+      "apiKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"},
+      "bearer": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"}
+    },
+    "parameters": {
+      "pipelineId": {
+        "name": "pipeline-id", "in": "path", "required": true,
+        "schema": {"$ref": "#/components/schemas/ID"}
+      },
+      "runId": {
+        "name": "run-id", "in": "path", "required": true,
+        "schema": {"$ref": "#/components/schemas/ID"}
+      },
+      "jobId": {
+        "name": "job-id", "in": "path", "required": true,
+        "schema": {"$ref": "#/components/schemas/ID"}
+      },
+      "priority": {
+        "name": "priority", "in": "query",
+        "description": "Admission priority of the request.",
+        "schema": {"type": "string", "enum": ["bulk", "interactive"]}
+      },
+      "outputs": {
+        "name": "outputs", "in": "query",
+        "description": "Comma separated names or keys of the pipeline outputs to return, all by default.",
+        "schema": {"type": "string"}
+      }
+    },
+    "requestBodies": {
+      "Dataset": {
+        "required": true,
+        "description": "A table or image dataset, depending on the dataset of the pipeline.",
+        "content": {
+          "application/json": {
+            "schema": {"oneOf": [{"$ref": "#/components/schemas/Table"}, {"$ref": "#/components/schemas/Image"}]}
+          }
+        }
+      }
+    },
+    "responses": {
+      "Error": {
+        "description": "The request failed.",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+      }
+    },
+    "schemas": {
+      "ID": {
+        "type": "string", "pattern": "^[A-Za-z0-9][A-Za-z0-9._-]*$", "maxLength": 128
+      },
+      "Error": {
+        "type": "object",
+        "required": ["code", "message"],
+        "properties": {
+          "code": {
+            "type": "string",
+            "enum": ["internal_error", "bad_request", "not_found", "conflict", "validation_failed", "unavailable", "unauthorized", "forbidden", "rate_limited"]
+          },
+          "message": {"type": "string"},
+          "details": {},
+          "requestId": {"type": "string"}
+        }
+      },
+      "Table": {
+        "type": "object",
+        "required": ["rows"],
+        "properties": {
+          "id": {"type": "string"},
+          "columns": {"type": "array", "items": {"type": "string"}},
+          "rows": {"type": "array", "items": {"$ref": "#/components/schemas/Row"}}
+        }
+      },
+      "Row": {
+        "type": "object",
+        "required": ["data"],
+        "properties": {
+          "id": {"type": "string"},
+          "data": {
+            "type": "object",
+            "additionalProperties": {
+              "nullable": true,
+              "oneOf": [{"type": "string"}, {"type": "number"}, {"type": "boolean"}]
+            }
+          }
+        }
+      },
+      "Image": {
+        "type": "object",
+        "required": ["images"],
+        "properties": {
+          "id": {"type": "string"},
+          "images": {"type": "array", "items": {"$ref": "#/components/schemas/ImageEncoded"}}
+        }
+      },
+      "ImageEncoded": {
+        "type": "object",
+        "required": ["id", "image"],
+        "properties": {
+          "id": {"$ref": "#/components/schemas/ID"},
+          "type": {"type": "string", "description": "Image format, ie: png or jpeg."},
+          "image": {"type": "string", "format": "byte"},
+          "label": {"type": "string"}
+        }
+      },
+      "PipelineUpload": {
+        "type": "object",
+        "required": ["datasetSchema", "pipeline", "problem"],
+        "properties": {
+          "datasetSchema": {"type": "object", "description": "D3M dataset document of the training dataset."},
+          "pipeline": {"type": "object", "description": "D3M pipeline description."},
+          "problem": {"type": "object", "description": "D3M problem document."}
+        }
+      },
+      "UploadResult": {
+        "type": "object",
+        "properties": {
+          "pipelineID": {"type": "string"},
+          "result": {"type": "string"}
+        }
+      },
+      "PipelineInfo": {
+        "type": "object",
+        "properties": {
+          "pipelineId": {"type": "string"},
+          "datasetId": {"type": "string"},
+          "uploadedTimestamp": {"type": "string", "format": "date-time"},
+          "fitted": {"type": "boolean"},
+          "fittedTimestamp": {"type": "string", "format": "date-time"}
+        }
+      },
+      "Prediction": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "value": {"type": "string"},
+          "confidence": {"type": "number"},
+          "probabilities": {"type": "object", "additionalProperties": {"type": "number"}}
+        }
+      },
+      "Output": {
+        "type": "object",
+        "properties": {
+          "key": {"type": "string"},
+          "name": {"type": "string"},
+          "columns": {"type": "array", "items": {"type": "string"}},
+          "rows": {"type": "array", "items": {"type": "array", "items": {"type": "string"}}}
+        }
+      },
+      "RowFailure": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "error": {"type": "string"},
+          "traceback": {"type": "string"},
+          "runId": {"type": "string"}
+        }
+      },
+      "Score": {
+        "type": "object",
+        "properties": {
+          "metric": {"type": "string"},
+          "value": {"type": "number"}
+        }
+      },
+      "ConfusionMatrix": {
+        "type": "object",
+        "properties": {
+          "labels": {"type": "array", "items": {"type": "string"}},
+          "counts": {"type": "array", "items": {"type": "array", "items": {"type": "integer"}}}
+        }
+      },
+      "FoldResult": {
+        "type": "object",
+        "properties": {
+          "fold": {"type": "integer"},
+          "trainCount": {"type": "integer"},
+          "testCount": {"type": "integer"},
+          "scores": {"type": "array", "items": {"$ref": "#/components/schemas/Score"}}
+        }
+      },
+      "ValidationResult": {
+        "type": "object",
+        "properties": {
+          "method": {"type": "string"},
+          "folds": {"type": "array", "items": {"$ref": "#/components/schemas/FoldResult"}},
+          "scores": {"type": "array", "items": {"$ref": "#/components/schemas/Score"}}
+        }
+      },
+      "CandidateResult": {
+        "type": "object",
+        "properties": {
+          "metric": {"type": "string"},
+          "threshold": {"type": "number"},
+          "holdoutCount": {"type": "integer"},
+          "currentScore": {"type": "number", "nullable": true},
+          "candidateScore": {"type": "number"},
+          "improvement": {"type": "number", "nullable": true},
+          "promoted": {"type": "boolean"},
+          "currentScores": {"type": "array", "items": {"$ref": "#/components/schemas/Score"}},
+          "candidateScores": {"type": "array", "items": {"$ref": "#/components/schemas/Score"}}
+        }
+      },
+      "FitResult": {
+        "type": "object",
+        "properties": {
+          "pipelineId": {"type": "string"},
+          "predictionId": {"type": "string"},
+          "fitted": {"type": "boolean"},
+          "runId": {"type": "string"},
+          "trainingRows": {"type": "integer"},
+          "validation": {"$ref": "#/components/schemas/ValidationResult"},
+          "candidate": {"$ref": "#/components/schemas/CandidateResult"}
+        }
+      },
+      "ProduceResult": {
+        "type": "object",
+        "properties": {
+          "pipelineId": {"type": "string"},
+          "predictionId": {"type": "string"},
+          "predictions": {"type": "array", "items": {"$ref": "#/components/schemas/Prediction"}},
+          "outputs": {"type": "object", "additionalProperties": {"$ref": "#/components/schemas/Output"}},
+          "runIds": {"type": "array", "items": {"type": "string"}},
+          "failures": {"type": "array", "items": {"$ref": "#/components/schemas/RowFailure"}}
+        }
+      },
+      "ScoreResult": {
+        "type": "object",
+        "properties": {
+          "pipelineId": {"type": "string"},
+          "predictionId": {"type": "string"},
+          "scores": {"type": "array", "items": {"$ref": "#/components/schemas/Score"}},
+          "confusionMatrix": {"$ref": "#/components/schemas/ConfusionMatrix"},
+          "count": {"type": "integer"},
+          "unmatched": {"type": "integer"},
+          "failures": {"type": "array", "items": {"$ref": "#/components/schemas/RowFailure"}}
+        }
+      },
+      "RunInfo": {
+        "type": "object",
+        "properties": {
+          "runId": {"type": "string"},
+          "pipelineId": {"type": "string"},
+          "command": {"type": "string", "enum": ["fit", "produce"]},
+          "startTime": {"type": "string", "format": "date-time"},
+          "endTime": {"type": "string", "format": "date-time"},
+          "success": {"type": "boolean"},
+          "error": {"type": "string"},
+          "traceback": {"type": "string"},
+          "pipelineRun": {"type": "boolean"}
+        }
+      },
+      "Job": {
+        "type": "object",
+        "properties": {
+          "jobId": {"type": "string"},
+          "type": {"type": "string", "enum": ["fit", "produce", "score"]},
+          "pipelineId": {"type": "string"},
+          "predictionId": {"type": "string"},
+          "status": {"type": "string", "enum": ["pending", "running", "succeeded", "failed"]},
+          "error": {"type": "string"},
+          "rowsTotal": {"type": "integer"},
+          "rowsProcessed": {"type": "integer"},
+          "rowsFailed": {"type": "integer"},
+          "runIds": {"type": "array", "items": {"type": "string"}},
+          "createdTime": {"type": "string", "format": "date-time"},
+          "updatedTime": {"type": "string", "format": "date-time"}
+        }
+      },
+      "Readiness": {
+        "type": "object",
+        "properties": {
+          "ready": {"type": "boolean"},
+          "checks": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "name": {"type": "string"},
+                "ok": {"type": "boolean"},
+                "message": {"type": "string"}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "security": [{"apiKey": []}, {"bearer": []}, {}],
+  "paths": {
+    "/distil/openapi.json": {
+      "get": {
+        "operationId": "getOpenAPI",
+        "summary": "Returns this document.",
+        "security": [],
+        "responses": {"200": {"description": "The OpenAPI document.", "content": {"application/json": {}}}}
+      }
+    },
+    "/distil/pipelines": {
+      "get": {
+        "operationId": "listPipelines",
+        "summary": "Lists the pipelines the caller can access.",
+        "responses": {
+          "200": {
+            "description": "The pipelines.",
+            "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/PipelineInfo"}}}}
+          },
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/distil/config": {
+      "get": {
+        "operationId": "getConfig",
+        "summary": "Returns the version of the service and its pipeline folder.",
+        "responses": {
+          "200": {
+            "description": "The configuration.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "version": {"type": "string"},
+                    "timestamp": {"type": "string"},
+                    "pipelineDir": {"type": "string"}
+                  }
+                }
+              }
+            }
+          },
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/distil/runs/{pipeline-id}": {
+      "get": {
+        "operationId": "listRuns",
+        "summary": "Lists the runner invocations of a pipeline.",
+        "parameters": [{"$ref": "#/components/parameters/pipelineId"}],
+        "responses": {
+          "200": {
+            "description": "The runs.",
+            "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/RunInfo"}}}}
+          },
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/distil/runs/{pipeline-id}/{run-id}": {
+      "get": {
+        "operationId": "getRun",
+        "summary": "Describes a runner invocation.",
+        "parameters": [{"$ref": "#/components/parameters/pipelineId"}, {"$ref": "#/components/parameters/runId"}],
+        "responses": {
+          "200": {"description": "The run.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/RunInfo"}}}},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/distil/runs/{pipeline-id}/{run-id}/{file}": {
+      "get": {
+        "operationId": "getRunFile",
+        "summary": "Returns the captured output or the D3M pipeline run document of a run.",
+        "parameters": [
+          {"$ref": "#/components/parameters/pipelineId"},
+          {"$ref": "#/components/parameters/runId"},
+          {"name": "file", "in": "path", "required": true, "description": "One of stdout, stderr or pipeline_run.", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "The file.", "content": {"text/plain": {}, "application/x-yaml": {}}},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/distil/jobs/{job-id}": {
+      "get": {
+        "operationId": "getJob",
+        "summary": "Returns the status of the job tracking a fit, produce or score request.",
+        "parameters": [{"$ref": "#/components/parameters/jobId"}],
+        "responses": {
+          "200": {"description": "The job.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/metrics": {
+      "get": {
+        "operationId": "getMetrics",
+        "summary": "Returns the Prometheus metrics of the service.",
+        "responses": {
+          "200": {"description": "The metrics.", "content": {"text/plain": {}}},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/healthz": {
+      "get": {
+        "operationId": "getHealth",
+        "summary": "Reports the service is alive.",
+        "security": [],
+        "responses": {
+          "200": {
+            "description": "The service is alive.",
+            "content": {"application/json": {"schema": {"type": "object", "properties": {"status": {"type": "string"}}}}}
+          }
+        }
+      }
+    },
+    "/readyz": {
+      "get": {
+        "operationId": "getReadiness",
+        "summary": "Reports whether the service is able to fit and produce.",
+        "security": [],
+        "responses": {
+          "200": {"description": "The service is ready.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Readiness"}}}},
+          "503": {"description": "The service is not ready.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Readiness"}}}}
+        }
+      }
+    },
+    "/distil/fit/{pipeline-id}": {
+      "post": {
+        "operationId": "fit",
+        "summary": "Fits the pipeline using the labelled dataset.",
+        "parameters": [
+          {"$ref": "#/components/parameters/pipelineId"},
+          {"$ref": "#/components/parameters/priority"},
+          {"name": "validation", "in": "query", "description": "Validates the pipeline before fitting it.", "schema": {"type": "string", "enum": ["holdout", "kfold"]}},
+          {"name": "holdout", "in": "query", "description": "Ratio of rows held out for holdout validation.", "schema": {"type": "number"}},
+          {"name": "folds", "in": "query", "description": "Number of folds of kfold validation.", "schema": {"type": "integer"}},
+          {"name": "seed", "in": "query", "description": "Seed of the random splits.", "schema": {"type": "integer"}},
+          {"name": "mode", "in": "query", "description": "Fits a candidate only promoted if it beats the fitted pipeline.", "schema": {"type": "string", "enum": ["candidate"]}},
+          {"name": "metric", "in": "query", "description": "Metric used to compare candidates.", "schema": {"type": "string"}},
+          {"name": "threshold", "in": "query", "description": "Improvement required to promote a candidate.", "schema": {"type": "number"}},
+          {"name": "training", "in": "query", "description": "Fits on the training data accumulated so far.", "schema": {"type": "string", "enum": ["append", "replace"]}}
+        ],
+        "requestBody": {"$ref": "#/components/requestBodies/Dataset"},
+        "responses": {
+          "200": {"description": "The fit outcome.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/FitResult"}}}},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/distil/produce/{pipeline-id}": {
+      "post": {
+        "operationId": "produce",
+        "summary": "Produces predictions for the dataset using the fitted pipeline.",
+        "parameters": [
+          {"$ref": "#/components/parameters/pipelineId"},
+          {"$ref": "#/components/parameters/priority"},
+          {"$ref": "#/components/parameters/outputs"}
+        ],
+        "requestBody": {"$ref": "#/components/requestBodies/Dataset"},
+        "responses": {
+          "200": {"description": "The predictions.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ProduceResult"}}}},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/distil/score/{pipeline-id}": {
+      "post": {
+        "operationId": "score",
+        "summary": "Produces predictions for the labelled dataset and scores them.",
+        "parameters": [
+          {"$ref": "#/components/parameters/pipelineId"},
+          {"$ref": "#/components/parameters/priority"}
+        ],
+        "requestBody": {"$ref": "#/components/requestBodies/Dataset"},
+        "responses": {
+          "200": {"description": "The scores.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ScoreResult"}}}},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/distil/upload/{pipeline-id}": {
+      "post": {
+        "operationId": "upload",
+        "summary": "Stores a pipeline, or its fitted form when the type is fitted.",
+        "parameters": [
+          {"$ref": "#/components/parameters/pipelineId"},
+          {"name": "type", "in": "query", "description": "Set to fitted to upload a fitted pipeline as a multipart file.", "schema": {"type": "string"}},
+          {"name": "overwrite", "in": "query", "description": "Replaces an existing pipeline, true by default.", "schema": {"type": "boolean"}}
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {"schema": {"$ref": "#/components/schemas/PipelineUpload"}},
+            "multipart/form-data": {
+              "schema": {
+                "type": "object",
+                "required": ["file"],
+                "properties": {"file": {"type": "string", "format": "binary"}}
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {"description": "The pipeline was stored.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/UploadResult"}}}},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    }
+  }
+}
+`
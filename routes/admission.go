@@ -0,0 +1,73 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"goji.io/v3/pattern"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/admission"
+)
+
+var (
+	admissionController *admission.Controller
+	rateLimits          *admission.Limits
+)
+
+// SetAdmission sets the controller bounding concurrent work and the rate
+// limits applied per client and per pipeline. Nil values disable them.
+func SetAdmission(controller *admission.Controller, limits *admission.Limits) {
+	admissionController = controller
+	rateLimits = limits
+}
+
+// Admit rate limits the request and waits for the admission controller to
+// let it through. The priority can be set with the priority query parameter
+// and otherwise defaults to the one specified.
+func Admit(defaultPriority admission.Priority, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		priority := defaultPriority
+		if name := r.URL.Query().Get("priority"); name != "" {
+			var err error
+			priority, err = admission.ParsePriority(name)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+		}
+
+		pipelineID, _ := r.Context().Value(pattern.Variable("pipeline-id")).(string)
+		wait, err := rateLimits.Allow(admission.ClientKey(r.Context(), r.RemoteAddr), pipelineID)
+		if err != nil {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(wait.Seconds()))))
+			handleError(w, err)
+			return
+		}
+
+		release, err := admissionController.Acquire(r.Context(), priority)
+		if err != nil {
+			w.Header().Set("Retry-After", "1")
+			handleError(w, err)
+			return
+		}
+		defer release()
+
+		handler(w, r.WithContext(admission.NewContext(r.Context(), priority)))
+	}
+}
@@ -0,0 +1,94 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	log "github.com/unchartedsoftware/plog"
+	"goji.io/v3/pattern"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+var (
+	authenticator *auth.Authenticator
+)
+
+// SetAuthenticator sets the authenticator used to identify callers. A nil
+// authenticator disables authentication.
+func SetAuthenticator(a *auth.Authenticator) {
+	authenticator = a
+}
+
+// Authenticate identifies the caller of the request from its credentials,
+// rejecting requests with invalid credentials. Requests without credentials
+// are let through so public routes remain reachable.
+func Authenticate(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if authenticator == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := authenticator.Authenticate(r)
+		if err != nil {
+			handleUnauthorized(w, err)
+			return
+		}
+		if principal != nil {
+			r = r.WithContext(auth.NewContext(r.Context(), principal))
+		}
+		h.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// Authorize only lets through callers granted the scope and, for routes
+// acting on a pipeline, access to that pipeline.
+func Authorize(scope auth.Scope, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if authenticator == nil {
+			handler(w, r)
+			return
+		}
+
+		principal := auth.FromContext(r.Context())
+		if principal == nil {
+			handleUnauthorized(w, util.NewUnauthorizedError("credentials required"))
+			return
+		}
+		if !principal.HasScope(scope) {
+			handleError(w, util.NewForbiddenError("'%s' is not granted the '%s' scope", principal.Name, scope))
+			return
+		}
+
+		pipelineID, _ := r.Context().Value(pattern.Variable("pipeline-id")).(string)
+		if pipelineID != "" && !principal.CanAccess(pipelineID) {
+			handleError(w, util.NewForbiddenError("'%s' is not granted access to pipeline '%s'", principal.Name, pipelineID))
+			return
+		}
+
+		log.Infof("'%s' authorized for %s on '%s'", principal.Name, scope, r.URL.Path)
+		handler(w, r)
+	}
+}
+
+func handleUnauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="distil-pipeline-executer"`)
+	handleError(w, err)
+}
@@ -0,0 +1,28 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"context"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+)
+
+// parseDataset parses the request body into the type of dataset expected by
+// the pipeline.
+func parseDataset(ctx context.Context, pipelineID string, requestBody []byte) (task.DatasetConstructor, error) {
+	return task.ParseDataset(ctx, pipelineID, requestBody, task.FormatJSON)
+}
@@ -16,30 +16,166 @@
 package routes
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
+	"sync/atomic"
 
-	"github.com/unchartedsoftware/plog"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// RequestIDHeader is the header holding the id of a request.
+	RequestIDHeader = "X-Request-ID"
+
+	// ErrorCodeInternal is returned for unexpected server failures.
+	ErrorCodeInternal = "internal_error"
+	// ErrorCodeBadRequest is returned for malformed requests.
+	ErrorCodeBadRequest = "bad_request"
+	// ErrorCodeNotFound is returned for unknown pipelines.
+	ErrorCodeNotFound = "not_found"
+	// ErrorCodeConflict is returned for requests conflicting with the current state.
+	ErrorCodeConflict = "conflict"
+	// ErrorCodeValidation is returned for requests failing validation.
+	ErrorCodeValidation = "validation_failed"
+	// ErrorCodeUnavailable is returned when the service cannot accept more work.
+	ErrorCodeUnavailable = "unavailable"
+	// ErrorCodeUnauthorized is returned for requests without valid credentials.
+	ErrorCodeUnauthorized = "unauthorized"
+	// ErrorCodeForbidden is returned for requests the credentials do not allow.
+	ErrorCodeForbidden = "forbidden"
+	// ErrorCodeRateLimited is returned for requests exceeding their rate limit.
+	ErrorCodeRateLimited = "rate_limited"
 )
 
 var (
-	verboseError = false
+	verboseError int32
 )
 
+// Error is the body of error responses.
+type Error struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"requestId,omitempty"`
+}
+
 // SetVerboseError sets the flag determining if the client should receive
 // error details
 func SetVerboseError(verbose bool) {
-	verboseError = verbose
+	value := int32(0)
+	if verbose {
+		value = 1
+	}
+	atomic.StoreInt32(&verboseError, value)
+}
+
+// RequestID assigns an id to every request, reusing the one supplied by the
+// client if any, and echoes it in the response headers.
+func RequestID(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		h.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	_, err := rand.Read(id)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
 }
 
+// handleError reports the error using the status code matching its kind.
 func handleError(w http.ResponseWriter, err error) {
-	handleErrorType(w, err, http.StatusInternalServerError)
+	kind, _ := util.GetErrorKind(err)
+	handleErrorType(w, err, getStatusCode(kind))
 }
 
 func handleErrorType(w http.ResponseWriter, err error, code int) {
 	log.Errorf("%+v", err)
+	if recorder, ok := w.(*jobRecorder); ok {
+		recorder.err = err
+	}
+	kind, details := util.GetErrorKind(err)
+
+	// client errors are always explained, server errors only if verbose
 	errMessage := "An error occured on the server while processing the request"
-	if verboseError {
+	if atomic.LoadInt32(&verboseError) == 1 || code < http.StatusInternalServerError || kind == util.KindUnavailable {
 		errMessage = err.Error()
+	} else {
+		details = nil
+	}
+
+	body, err := json.Marshal(&Error{
+		Code:      getErrorCode(code),
+		Message:   errMessage,
+		Details:   details,
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+	if err != nil {
+		http.Error(w, errMessage, code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+func getStatusCode(kind util.ErrorKind) int {
+	switch kind {
+	case util.KindBadRequest:
+		return http.StatusBadRequest
+	case util.KindNotFound:
+		return http.StatusNotFound
+	case util.KindConflict:
+		return http.StatusConflict
+	case util.KindInvalid:
+		return http.StatusUnprocessableEntity
+	case util.KindUnavailable:
+		return http.StatusServiceUnavailable
+	case util.KindUnauthorized:
+		return http.StatusUnauthorized
+	case util.KindForbidden:
+		return http.StatusForbidden
+	case util.KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func getErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusUnprocessableEntity:
+		return ErrorCodeValidation
+	case http.StatusServiceUnavailable:
+		return ErrorCodeUnavailable
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusTooManyRequests:
+		return ErrorCodeRateLimited
+	default:
+		return ErrorCodeInternal
 	}
-	http.Error(w, errMessage, code)
 }
@@ -18,14 +18,16 @@ package routes
 import (
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/pkg/errors"
 	log "github.com/unchartedsoftware/plog"
 	"goji.io/v3/pat"
 
-	"github.com/uncharted-distil/distil-pipeline-executer/dataset"
 	"github.com/uncharted-distil/distil-pipeline-executer/env"
 	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
 )
 
 // FitHandler takes in labelled data and trains the specified pipeline.
@@ -36,57 +38,131 @@ func FitHandler(config *env.Config) func(http.ResponseWriter, *http.Request) {
 		//typ := pat.Param(r, "type")
 		//format := pat.Param(r, "format")
 
-		// parse the input data
-		requestBody, err := ioutil.ReadAll(r.Body)
+		// validation options are query params since the body is the data
+		validation, err := parseValidationOptions(r.URL.Query())
 		if err != nil {
-			handleError(w, errors.Wrapf(err, "unable to read request body"))
+			handleError(w, err)
 			return
 		}
-		defer r.Body.Close()
-
-		log.Infof("unmarshalling request body")
-		datasetType, err := task.GetDatasetType(pipelineID)
+		candidate, err := parseCandidateOptions(r.URL.Query(), config)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
-
-		var ds task.DatasetConstructor
-		switch datasetType {
-		case dataset.ImageType:
-			ds, err = dataset.NewImageDataset(requestBody)
-		case dataset.TableType:
-			ds, err = dataset.NewTableDataset(requestBody)
-		case dataset.UnknownType:
-			err = errors.New("unsupproted dataset type")
-		}
+		training := r.URL.Query().Get("training")
+		err = task.CheckTrainingMode(training)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
 
-		// create the dataset to be used for the produce call
-		schemaPath, err := task.CreateDataset(pipelineID, ds)
+		// parse the input data
+		requestBody, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, util.WithKind(errors.Wrapf(err, "unable to read request body"), util.KindBadRequest))
+			return
+		}
+		defer r.Body.Close()
+
+		log.Infof("unmarshalling request body")
+		ds, err := parseDataset(r.Context(), pipelineID, requestBody)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
 
-		// run predictions on the newly created dataset
-		err = task.Fit(pipelineID, schemaPath, ds.GetPredictionsID(), config)
+		fitted, err := task.FitDataset(r.Context(), pipelineID, ds, &task.FitOptions{
+			Training:   training,
+			Validation: validation,
+			Candidate:  candidate,
+		}, config)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
 
-		err = handleJSON(w, map[string]interface{}{
+		result := map[string]interface{}{
 			"pipelineId":   pipelineID,
-			"predictionId": ds.GetPredictionsID(),
-			"fitted":       true,
-		})
+			"predictionId": fitted.PredictionsID,
+			"fitted":       fitted.Fitted,
+		}
+		if fitted.Validation != nil {
+			result["validation"] = fitted.Validation
+		}
+		if training != task.TrainingNone {
+			result["trainingRows"] = fitted.TrainingRows
+		}
+		if fitted.Candidate != nil {
+			result["candidate"] = fitted.Candidate
+		} else {
+			result["runId"] = fitted.RunID
+		}
+
+		err = handleJSON(w, result)
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable marshal produce result into JSON"))
 			return
 		}
 	}
 }
+
+func parseValidationOptions(query url.Values) (*task.ValidationOptions, error) {
+	options := &task.ValidationOptions{
+		Method:       query.Get("validation"),
+		HoldoutRatio: task.DefaultHoldoutRatio,
+		Folds:        task.DefaultFolds,
+	}
+
+	var err error
+	if query.Get("holdout") != "" {
+		options.HoldoutRatio, err = strconv.ParseFloat(query.Get("holdout"), 64)
+		if err != nil {
+			return nil, util.WithKind(errors.Wrap(err, "unable to parse holdout ratio"), util.KindBadRequest)
+		}
+	}
+	if query.Get("folds") != "" {
+		options.Folds, err = strconv.Atoi(query.Get("folds"))
+		if err != nil {
+			return nil, util.WithKind(errors.Wrap(err, "unable to parse fold count"), util.KindBadRequest)
+		}
+	}
+	if query.Get("seed") != "" {
+		options.Seed, err = strconv.ParseInt(query.Get("seed"), 10, 64)
+		if err != nil {
+			return nil, util.WithKind(errors.Wrap(err, "unable to parse seed"), util.KindBadRequest)
+		}
+	}
+
+	return options, nil
+}
+
+func parseCandidateOptions(query url.Values, config *env.Config) (*task.CandidateOptions, error) {
+	if query.Get("mode") != "candidate" {
+		return nil, nil
+	}
+
+	options := &task.CandidateOptions{
+		Metric:       config.PromotionMetric,
+		Threshold:    config.PromotionThreshold,
+		HoldoutRatio: config.PromotionHoldoutRatio,
+	}
+	if query.Get("metric") != "" {
+		options.Metric = query.Get("metric")
+	}
+
+	var err error
+	if query.Get("threshold") != "" {
+		options.Threshold, err = strconv.ParseFloat(query.Get("threshold"), 64)
+		if err != nil {
+			return nil, util.WithKind(errors.Wrap(err, "unable to parse promotion threshold"), util.KindBadRequest)
+		}
+	}
+	if query.Get("seed") != "" {
+		options.Seed, err = strconv.ParseInt(query.Get("seed"), 10, 64)
+		if err != nil {
+			return nil, util.WithKind(errors.Wrap(err, "unable to parse seed"), util.KindBadRequest)
+		}
+	}
+
+	return options, nil
+}
@@ -0,0 +1,63 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+)
+
+// HealthzHandler reports the process is alive and serving requests.
+func HealthzHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := handleJSON(w, map[string]interface{}{
+			"status": "ok",
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal health into JSON and write response"))
+			return
+		}
+	}
+}
+
+// ReadyzHandler reports whether the service is able to fit and produce,
+// returning the outcome of every check with a 503 if any of them failed.
+func ReadyzHandler(config *env.Config) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		readiness := task.CheckReadiness(r.Context(), config)
+
+		code := http.StatusOK
+		if !readiness.Ready {
+			for _, c := range readiness.Checks {
+				if !c.OK {
+					log.Warnf("readiness check '%s' failed: %s", c.Name, c.Message)
+				}
+			}
+			code = http.StatusServiceUnavailable
+		}
+
+		err := handleJSONStatus(w, code, readiness)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal readiness into JSON and write response"))
+			return
+		}
+	}
+}
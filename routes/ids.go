@@ -0,0 +1,50 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"goji.io/v3/pattern"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+var (
+	idVariables = []pattern.Variable{"pipeline-id", "run-id", "job-id"}
+)
+
+// ValidateIDs rejects requests whose route ids do not match the id policy
+// before they get anywhere near the file system.
+func ValidateIDs(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		for _, v := range idVariables {
+			id, ok := r.Context().Value(v).(string)
+			if !ok {
+				continue
+			}
+			err := env.ValidateID(id)
+			if err != nil {
+				handleError(w, util.WithKind(errors.Wrapf(err, "invalid %s", v), util.KindBadRequest))
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,109 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+	"goji.io/v3/pat"
+	"goji.io/v3/pattern"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// JobIDHeader is the header holding the id of the job tracking a request.
+	JobIDHeader = "X-Job-ID"
+)
+
+// jobRecorder captures the outcome of a tracked request.
+type jobRecorder struct {
+	http.ResponseWriter
+	status int
+	err    error
+}
+
+func (r *jobRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// TrackJob persists the progress and outcome of the request as a job. Every
+// request gets a new job, whose id is returned in the job id header. It is
+// meant to run once the request is admitted so rejected requests leave no
+// job behind.
+func TrackJob(jobs *task.JobStore, jobType string, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pipelineID, _ := r.Context().Value(pattern.Variable("pipeline-id")).(string)
+
+		job, err := jobs.Create(env.NewID(), jobType, pipelineID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		w.Header().Set(JobIDHeader, job.JobID)
+		err = job.Start()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		recorder := &jobRecorder{
+			ResponseWriter: w,
+			status:         http.StatusOK,
+		}
+		handler(recorder, r.WithContext(task.NewJobContext(r.Context(), job)))
+
+		err = recorder.err
+		if err == nil && recorder.status >= http.StatusBadRequest {
+			err = errors.Errorf("request failed with status %d", recorder.status)
+		}
+		err = job.Finish(err)
+		if err != nil {
+			log.Warnf("unable to finish job '%s': %+v", job.JobID, err)
+		}
+	}
+}
+
+// JobHandler returns the status of a job.
+func JobHandler(jobs *task.JobStore) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobID := pat.Param(r, "job-id")
+		job, err := jobs.Get(jobID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// jobs of pipelines the caller cannot access do not exist for them
+		if principal := auth.FromContext(r.Context()); principal != nil && !principal.CanAccess(job.PipelineID) {
+			handleError(w, util.NewNotFoundError("job '%s' not found", jobID))
+			return
+		}
+
+		err = handleJSON(w, job)
+		if err != nil {
+			handleError(w, errors.Wrap(err, fmt.Sprintf("unable marshal job '%s' into JSON", jobID)))
+			return
+		}
+	}
+}
@@ -22,6 +22,10 @@ import (
 )
 
 func handleJSON(w http.ResponseWriter, data interface{}) error {
+	return handleJSONStatus(w, http.StatusOK, data)
+}
+
+func handleJSONStatus(w http.ResponseWriter, code int, data interface{}) error {
 	// marshal data
 	bytes, err := json.Marshal(data)
 	if err != nil {
@@ -29,6 +33,7 @@ func handleJSON(w http.ResponseWriter, data interface{}) error {
 	}
 	// send response
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
 	w.Write(bytes)
 	return nil
 }
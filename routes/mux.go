@@ -0,0 +1,102 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	log "github.com/unchartedsoftware/plog"
+	goji "goji.io/v3"
+	"goji.io/v3/pat"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/admission"
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
+	"github.com/uncharted-distil/distil/api/middleware"
+)
+
+// Route is an API route along with its handler. Requests to routes with a
+// scope need to be authorized for it before reaching the handler.
+type Route struct {
+	Method  string
+	Pattern string
+	Scope   auth.Scope
+	Handler func(http.ResponseWriter, *http.Request)
+}
+
+// Routes lists the routes of the API, sharing the queue of rows to produce
+// and the job registry.
+func Routes(config *env.Config, queue *task.Queue, jobs *task.JobStore, version string, timestamp string) []*Route {
+	return []*Route{
+		// GET
+		{http.MethodGet, "/distil/pipelines", auth.ScopeRead, PipelinesHandler(*config)},
+		{http.MethodGet, "/distil/config", auth.ScopeRead, ConfigHandler(*config, version, timestamp)},
+		{http.MethodGet, "/distil/runs/:pipeline-id", auth.ScopeRead, RunsHandler()},
+		{http.MethodGet, "/distil/runs/:pipeline-id/:run-id", auth.ScopeRead, RunHandler()},
+		{http.MethodGet, "/distil/runs/:pipeline-id/:run-id/:file", auth.ScopeRead, RunFileHandler()},
+		{http.MethodGet, "/distil/jobs/:job-id", auth.ScopeRead, JobHandler(jobs)},
+		{http.MethodGet, "/metrics", auth.ScopeRead, metrics.Handler().ServeHTTP},
+		{http.MethodGet, "/distil/openapi.json", "", OpenAPIHandler()},
+		{http.MethodGet, "/healthz", "", HealthzHandler()},
+		{http.MethodGet, "/readyz", "", ReadyzHandler(config)},
+
+		// POST
+		{http.MethodPost, "/distil/fit/:pipeline-id", auth.ScopeFit, Admit(admission.PriorityBulk, TrackJob(jobs, task.JobFit, FitHandler(config)))},
+		{http.MethodPost, "/distil/produce/:pipeline-id", auth.ScopeProduce, Admit(admission.PriorityInteractive, TrackJob(jobs, task.JobProduce, ProduceHandler(config, queue)))},
+		{http.MethodPost, "/distil/score/:pipeline-id", auth.ScopeProduce, Admit(admission.PriorityInteractive, TrackJob(jobs, task.JobScore, ScoreHandler(config, queue)))},
+		{http.MethodPost, "/distil/upload/:pipeline-id", auth.ScopeAdmin, UploadHandler(config.PipelineDir)},
+
+		// static
+		{http.MethodGet, "/*", "", FileHandler("./dist")},
+	}
+}
+
+// NewMux creates the mux serving the routes behind the middleware shared by
+// all of them.
+func NewMux(config *env.Config, routes []*Route) *goji.Mux {
+	mux := goji.NewMux()
+	mux.Use(RequestID)
+	mux.Use(middleware.Log)
+	mux.Use(middleware.Gzip)
+	mux.Use(metrics.Middleware)
+	mux.Use(tracing.Middleware)
+	mux.Use(ValidateIDs)
+	mux.Use(Authenticate)
+
+	for _, route := range routes {
+		log.Infof("Registering %s route %s", route.Method, route.Pattern)
+
+		// requests are only validated once authorized
+		handler := route.Handler
+		if config.RequestValidation {
+			handler = ValidateRequest(handler)
+		}
+		if route.Scope != "" {
+			handler = Authorize(route.Scope, handler)
+		}
+
+		if route.Method == http.MethodPost {
+			mux.HandleFunc(pat.Post(route.Pattern), handler)
+		} else {
+			mux.HandleFunc(pat.Get(route.Pattern), handler)
+		}
+	}
+
+	return mux
+}
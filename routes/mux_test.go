@@ -0,0 +1,146 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/openapi"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+)
+
+func loadTestConfig(t *testing.T) (*env.Config, string) {
+	dir, err := ioutil.TempDir("", "routes-test")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	log.SetLevel(log.WarnLevel)
+	config, err := env.LoadConfig()
+	if err != nil {
+		t.Fatalf("unable to load config: %v", err)
+	}
+	config.JobDir = path.Join(dir, "jobs")
+	return &config, dir
+}
+
+func TestRoutesDocumented(t *testing.T) {
+	config, dir := loadTestConfig(t)
+	defer os.RemoveAll(dir)
+	jobs, err := task.NewJobStore(config.JobDir)
+	if err != nil {
+		t.Fatalf("unable to create job store: %v", err)
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range Routes(config, task.NewQueue(0), jobs, "test", "test") {
+		// static files are not part of the API
+		if route.Pattern == "/*" {
+			continue
+		}
+		specPath := openAPIPath(route.Pattern)
+		registered[strings.ToLower(route.Method)+" "+specPath] = true
+		if openapi.Spec().Operation(route.Method, specPath) == nil {
+			t.Errorf("%s %s is not described by the OpenAPI document", route.Method, specPath)
+		}
+	}
+
+	for specPath, operations := range openapi.Spec().Paths {
+		for method := range operations {
+			if !registered[method+" "+specPath] {
+				t.Errorf("%s %s is described by the OpenAPI document but not registered", method, specPath)
+			}
+		}
+	}
+}
+
+func TestValidateAfterAuthorize(t *testing.T) {
+	config, dir := loadTestConfig(t)
+	defer os.RemoveAll(dir)
+
+	keysFile := path.Join(dir, "keys.json")
+	err := ioutil.WriteFile(keysFile, []byte(`[
+		{"name": "reader", "key": "read-key", "scopes": ["read"]},
+		{"name": "producer", "key": "produce-key", "scopes": ["produce"], "pipelines": ["test"]}
+	]`), 0644)
+	if err != nil {
+		t.Fatalf("unable to write keys: %v", err)
+	}
+	config.AuthEnabled = true
+	config.AuthKeysFile = keysFile
+	config.RequestValidation = true
+	authenticator, err := auth.NewAuthenticator(config)
+	if err != nil {
+		t.Fatalf("unable to create authenticator: %v", err)
+	}
+	SetAuthenticator(authenticator)
+	defer SetAuthenticator(nil)
+
+	handled := 0
+	server := httptest.NewServer(NewMux(config, []*Route{
+		{http.MethodPost, "/distil/produce/:pipeline-id", auth.ScopeProduce, func(w http.ResponseWriter, r *http.Request) {
+			handled++
+		}},
+	}))
+	defer server.Close()
+
+	valid := `{"rows": [{"id": "0", "data": {"x": "a"}}]}`
+	invalid := `{"rows": "none"}`
+	tests := []struct {
+		name   string
+		key    string
+		body   string
+		status int
+	}{
+		{"anonymous invalid", "", invalid, http.StatusUnauthorized},
+		{"unauthorized invalid", "read-key", invalid, http.StatusForbidden},
+		{"authorized invalid", "produce-key", invalid, http.StatusUnprocessableEntity},
+		{"authorized valid", "produce-key", valid, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/distil/produce/test", strings.NewReader(test.body))
+			if err != nil {
+				t.Fatalf("unable to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if test.key != "" {
+				req.Header.Set(auth.APIKeyHeader, test.key)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			res.Body.Close()
+			if res.StatusCode != test.status {
+				t.Errorf("expected status %d, got %d", test.status, res.StatusCode)
+			}
+		})
+	}
+	if handled != 1 {
+		t.Errorf("expected only the valid request to be handled, got %d", handled)
+	}
+}
@@ -0,0 +1,98 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"goji.io/v3/middleware"
+	"goji.io/v3/pat"
+	"goji.io/v3/pattern"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/openapi"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// OpenAPIHandler returns the OpenAPI document describing the routes.
+func OpenAPIHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openapi.Spec().JSON())
+	}
+}
+
+// ValidateRequest rejects requests whose parameters or json body do not
+// match the OpenAPI document. Routes missing from the document are let
+// through.
+func ValidateRequest(handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := middleware.Pattern(r.Context()).(*pat.Pattern)
+		if !ok {
+			handler(w, r)
+			return
+		}
+		operation := openapi.Spec().Operation(r.Method, openAPIPath(p.String()))
+		if operation == nil {
+			handler(w, r)
+			return
+		}
+
+		err := operation.ValidateParameters(r.URL.Query(), func(name string) (string, bool) {
+			value, ok := r.Context().Value(pattern.Variable(name)).(string)
+			return value, ok
+		})
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		contentType := r.Header.Get("Content-Type")
+		if operation.RequiresBody(contentType) {
+			// the body is read in full to validate it, then handed over as is
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				handleError(w, util.WithKind(errors.Wrap(err, "unable to read request body"), util.KindBadRequest))
+				return
+			}
+			r.Body.Close()
+
+			err = operation.ValidateBody(contentType, body)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		handler(w, r)
+	}
+}
+
+// openAPIPath converts a route pattern to the OpenAPI path template syntax,
+// ie: /distil/fit/:pipeline-id becomes /distil/fit/{pipeline-id}.
+func openAPIPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
 	"github.com/uncharted-distil/distil-pipeline-executer/env"
 	"github.com/uncharted-distil/distil-pipeline-executer/task"
 )
@@ -33,6 +34,17 @@ func PipelinesHandler(config env.Config) func(http.ResponseWriter, *http.Request
 			return
 		}
 
+		// only list the pipelines the caller can access
+		if principal := auth.FromContext(r.Context()); principal != nil {
+			accessible := make([]*task.PipelineInfo, 0)
+			for _, p := range pipelines {
+				if principal.CanAccess(p.PipelineID) {
+					accessible = append(accessible, p)
+				}
+			}
+			pipelines = accessible
+		}
+
 		err = handleJSON(w, pipelines)
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable marshal version into JSON and write response"))
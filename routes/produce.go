@@ -18,13 +18,12 @@ package routes
 import (
 	"io/ioutil"
 	"net/http"
-	"path"
+	"strings"
 
 	"github.com/pkg/errors"
 	"goji.io/v3/pat"
 
-	"github.com/uncharted-distil/distil-compute/metadata"
-	"github.com/uncharted-distil/distil-pipeline-executer/dataset"
+	"github.com/uncharted-distil/distil-pipeline-executer/admission"
 	"github.com/uncharted-distil/distil-pipeline-executer/env"
 	"github.com/uncharted-distil/distil-pipeline-executer/task"
 	"github.com/uncharted-distil/distil-pipeline-executer/util"
@@ -32,95 +31,49 @@ import (
 )
 
 // Prediction is a result from a produce call.
-type Prediction struct {
-	ID    string `json:"id"`
-	Value string `json:"value"`
-}
+type Prediction = task.Prediction
 
 // ProduceHandler takes in unlabelled data and generates predictions using
 // a fitted model.
-func ProduceHandler(config *env.Config) func(http.ResponseWriter, *http.Request) {
+func ProduceHandler(config *env.Config, queue *task.Queue) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		pipelineID := pat.Param(r, "pipeline-id")
 		log.Infof("produce request received for pipeline '%s'", pipelineID)
 		//typ := pat.Param(r, "type")
 		//format := pat.Param(r, "format")
 
+		// outputs to return can be listed by name or key, defaulting to all
+		selectedOutputs := parseOutputSelection(r.URL.Query().Get("outputs"))
+
 		// parse the input data
 		requestBody, err := ioutil.ReadAll(r.Body)
 		if err != nil {
-			handleError(w, errors.Wrapf(err, "unable to read request body"))
+			handleError(w, util.WithKind(errors.Wrapf(err, "unable to read request body"), util.KindBadRequest))
 			return
 		}
 		defer r.Body.Close()
 
-		datasetType, err := task.GetDatasetType(pipelineID)
-		if err != nil {
-			handleError(w, err)
-			return
-		}
-
-		var ds task.DatasetConstructor
-		switch datasetType {
-		case dataset.ImageType:
-			ds, err = dataset.NewImageDataset(requestBody)
-		case dataset.TableType:
-			ds, err = dataset.NewTableDataset(requestBody)
-		case dataset.UnknownType:
-			err = errors.New("unsupproted dataset type")
-		}
-		if err != nil {
-			handleError(w, err)
-			return
-		}
-
-		// create the dataset to be used for the produce call
-		schemaPath, err := task.CreateDataset(pipelineID, ds)
-		if err != nil {
-			handleError(w, err)
-			return
-		}
-
-		data, err := readData(schemaPath)
+		ds, err := parseDataset(r.Context(), pipelineID, requestBody)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
 
-		queue := task.NewQueue()
-		queue.AddDataset(ds.GetPredictionsID())
-		for _, r := range data {
-			queue.AddEntry(ds.GetPredictionsID(), r)
-		}
-
-		// run predictions on the newly created dataset
-		predictions, err := task.ProduceBatch(pipelineID, schemaPath, ds.GetPredictionsID(), queue, config)
+		// queue the rows, served according to the request priority
+		priority := admission.FromContext(r.Context())
+		predictions, output, err := task.ProduceDataset(r.Context(), pipelineID, ds, queue, int(priority), config)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
 
-		// create the prediction output (skipping header)
-		output := make([]*Prediction, 0)
-		for _, p := range predictions {
-			output = append(output, &Prediction{
-				ID:    p[0],
-				Value: p[1],
-			})
-		}
-
-		if config.ClearDataset {
-			err = task.ClearDataset(pipelineID, ds.GetPredictionsID())
-			if err != nil {
-				handleError(w, errors.Wrap(err, "unable to read produce output"))
-				return
-			}
-		}
-
 		err = handleJSON(w, map[string]interface{}{
 			"pipelineId":   pipelineID,
 			"predictionId": ds.GetPredictionsID(),
 			"predictions":  output,
+			"outputs":      filterOutputs(predictions, selectedOutputs),
+			"runIds":       predictions.RunIDs,
+			"failures":     predictions.Failures,
 		})
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable marshal produce result into JSON"))
@@ -129,19 +82,24 @@ func ProduceHandler(config *env.Config) func(http.ResponseWriter, *http.Request)
 	}
 }
 
-func readData(schemaFilename string) ([][]string, error) {
-	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFilename, false)
-	if err != nil {
-		return nil, err
+func parseOutputSelection(param string) map[string]bool {
+	if param == "" || param == "all" {
+		return nil
 	}
 
-	mainDR := meta.GetMainDataResource()
-	dateFilename := path.Join(path.Dir(schemaFilename), mainDR.ResPath)
-
-	data, err := util.ReadCSVFile(dateFilename, true)
-	if err != nil {
-		return nil, err
+	selected := make(map[string]bool)
+	for _, o := range strings.Split(param, ",") {
+		selected[strings.TrimSpace(o)] = true
 	}
+	return selected
+}
 
-	return data, nil
+func filterOutputs(result *task.ProduceResult, selected map[string]bool) map[string]*task.Output {
+	outputs := make(map[string]*task.Output)
+	for key, o := range result.Outputs {
+		if selected == nil || selected[key] || selected[o.Name] {
+			outputs[o.Name] = o
+		}
+	}
+	return outputs
 }
@@ -0,0 +1,103 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/pkg/errors"
+	"goji.io/v3/pat"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+var (
+	runFiles = map[string]struct {
+		name        string
+		contentType string
+	}{
+		"stdout":       {task.RunStdoutFile, "text/plain; charset=utf-8"},
+		"stderr":       {task.RunStderrFile, "text/plain; charset=utf-8"},
+		"pipeline_run": {task.RunPipelineRunFile, "application/x-yaml"},
+	}
+)
+
+// RunsHandler returns the runner invocations of a pipeline.
+func RunsHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pipelineID := pat.Param(r, "pipeline-id")
+		runs, err := task.GetRuns(pipelineID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, runs)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal runs into JSON"))
+			return
+		}
+	}
+}
+
+// RunHandler returns the description of a single run.
+func RunHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		run, err := task.GetRun(pat.Param(r, "pipeline-id"), pat.Param(r, "run-id"))
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, run)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal run into JSON"))
+			return
+		}
+	}
+}
+
+// RunFileHandler returns the captured output or the D3M pipeline run
+// document of a run.
+func RunFileHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pipelineID := pat.Param(r, "pipeline-id")
+		runID := pat.Param(r, "run-id")
+		file, ok := runFiles[pat.Param(r, "file")]
+		if !ok {
+			handleError(w, util.NewNotFoundError("unknown run file '%s'", pat.Param(r, "file")))
+			return
+		}
+
+		// make sure the run exists before serving its files
+		_, err := task.GetRun(pipelineID, runID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		filename := path.Join(env.ResolveRunPath(pipelineID, runID), file.name)
+		if !util.FileExists(filename) {
+			handleError(w, util.NewNotFoundError("run '%s' has no %s", runID, pat.Param(r, "file")))
+			return
+		}
+
+		w.Header().Set("Content-Type", file.contentType)
+		http.ServeFile(w, r, filename)
+	}
+}
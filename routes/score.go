@@ -0,0 +1,137 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+	"goji.io/v3/pat"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/admission"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// ScoreHandler takes in labelled data, generates predictions using a fitted
+// model and evaluates them using the metrics of the pipeline problem.
+func ScoreHandler(config *env.Config, queue *task.Queue) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pipelineID := pat.Param(r, "pipeline-id")
+		log.Infof("score request received for pipeline '%s'", pipelineID)
+
+		// parse the input data
+		requestBody, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, util.WithKind(errors.Wrapf(err, "unable to read request body"), util.KindBadRequest))
+			return
+		}
+		defer r.Body.Close()
+
+		ds, err := parseDataset(r.Context(), pipelineID, requestBody)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if !task.IsFitted(pipelineID) {
+			handleError(w, util.WithKind(errors.Errorf("pipeline '%s' has not been fitted", pipelineID), util.KindConflict))
+			return
+		}
+
+		problem, err := task.LoadProblem(pipelineID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		target, err := problem.GetTarget()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// create the dataset to be used for the produce call
+		schemaPath, err := task.CreateDataset(r.Context(), pipelineID, ds)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		data, err := task.ReadLearningData(schemaPath)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// hide the labels from the pipeline
+		unlabelled, labels, err := task.SplitLabels(schemaPath, data, target)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// queue the rows, served according to the request priority
+		priority := admission.FromContext(r.Context())
+		err = task.EnqueueRows(r.Context(), queue, ds.GetPredictionsID(), int(priority), unlabelled)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// run predictions on the newly created dataset
+		predictions, err := task.ProduceBatch(r.Context(), pipelineID, schemaPath, ds.GetPredictionsID(), queue, config)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		predicted, err := task.GetPredictedValues(predictions.Predictions())
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		score, err := task.ScorePredictions(problem, labels, predicted)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		if config.ClearDataset {
+			err = task.ClearDataset(pipelineID, ds.GetPredictionsID())
+			if err != nil {
+				handleError(w, errors.Wrap(err, "unable to clear scoring dataset"))
+				return
+			}
+		}
+
+		err = handleJSON(w, map[string]interface{}{
+			"pipelineId":      pipelineID,
+			"predictionId":    ds.GetPredictionsID(),
+			"scores":          score.Scores,
+			"confusionMatrix": score.ConfusionMatrix,
+			"count":           score.Count,
+			"unmatched":       score.Unmatched,
+			"failures":        predictions.Failures,
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal score result into JSON"))
+			return
+		}
+	}
+}
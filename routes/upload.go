@@ -24,6 +24,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
 	"goji.io/v3/pat"
 )
 
@@ -43,12 +44,13 @@ func UploadHandler(pipelinesDir string) func(http.ResponseWriter, *http.Request)
 		// type cant be a post param since the upload is the actual data
 		queryValues := r.URL.Query()
 		typ := queryValues.Get("type")
+		overwrite := queryValues.Get("overwrite") != "false"
 
 		if typ == "fitted" {
 			// read the file from the request
 			data, err := receiveFile(r)
 			if err != nil {
-				handleError(w, errors.Wrap(err, "unable to receive file from request"))
+				handleError(w, util.WithKind(errors.Wrap(err, "unable to receive file from request"), util.KindBadRequest))
 				return
 			}
 
@@ -62,7 +64,7 @@ func UploadHandler(pipelinesDir string) func(http.ResponseWriter, *http.Request)
 			// need the pipeline in json form as well as the full dataset doc
 			requestBody, err := ioutil.ReadAll(r.Body)
 			if err != nil {
-				handleError(w, err)
+				handleError(w, util.WithKind(errors.Wrap(err, "unable to read request body"), util.KindBadRequest))
 				return
 			}
 			defer r.Body.Close()
@@ -70,20 +72,20 @@ func UploadHandler(pipelinesDir string) func(http.ResponseWriter, *http.Request)
 			var upload PipelineUpload
 			err = json.Unmarshal(requestBody, &upload)
 			if err != nil {
-				handleError(w, err)
+				handleError(w, util.WithKind(errors.Wrap(err, "unable to parse upload"), util.KindBadRequest))
 				return
 			}
 
 			if upload.DatasetSchema == nil {
-				handleError(w, errors.Errorf("dataset schema not provided in upload"))
+				handleError(w, util.NewInvalidError("dataset schema not provided in upload"))
 				return
 			}
 			if upload.Pipeline == nil {
-				handleError(w, errors.Errorf("pipeline not provided in upload"))
+				handleError(w, util.NewInvalidError("pipeline not provided in upload"))
 				return
 			}
 			if upload.Problem == nil {
-				handleError(w, errors.Errorf("problem not provided in upload"))
+				handleError(w, util.NewInvalidError("problem not provided in upload"))
 				return
 			}
 
@@ -104,7 +106,7 @@ func UploadHandler(pipelinesDir string) func(http.ResponseWriter, *http.Request)
 				return
 			}
 
-			err = task.StorePipeline(pipelineID, pipelineJSON, schemaJSON, problemJSON, true)
+			err = task.StorePipeline(pipelineID, pipelineJSON, schemaJSON, problemJSON, overwrite)
 			if err != nil {
 				handleError(w, err)
 				return
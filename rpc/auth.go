@@ -0,0 +1,103 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// authenticatedStream overrides the context of a stream to hold the caller.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate identifies the caller from the credentials found in the call
+// metadata, which uses the same keys as the HTTP headers.
+func authenticate(ctx context.Context, authenticator *auth.Authenticator) (context.Context, error) {
+	if authenticator == nil {
+		return ctx, nil
+	}
+
+	header := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		for _, v := range values {
+			header.Add(key, v)
+		}
+	}
+
+	principal, err := authenticator.AuthenticateHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	if principal != nil {
+		ctx = auth.NewContext(ctx, principal)
+	}
+	return ctx, nil
+}
+
+// authorize only lets through callers granted the scope and, for calls
+// acting on a pipeline, access to that pipeline.
+func authorize(ctx context.Context, authenticator *auth.Authenticator, scope auth.Scope, pipelineID string) error {
+	if authenticator == nil {
+		return nil
+	}
+
+	principal := auth.FromContext(ctx)
+	if principal == nil {
+		return util.NewUnauthorizedError("credentials required")
+	}
+	if !principal.HasScope(scope) {
+		return util.NewForbiddenError("'%s' is not granted the '%s' scope", principal.Name, scope)
+	}
+	if pipelineID != "" && !principal.CanAccess(pipelineID) {
+		return util.NewForbiddenError("'%s' is not granted access to pipeline '%s'", principal.Name, pipelineID)
+	}
+
+	return nil
+}
+
+func unaryAuthenticate(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, authenticator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuthenticate(authenticator *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), authenticator)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
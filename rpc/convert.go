@@ -0,0 +1,180 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package rpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/admission"
+	"github.com/uncharted-distil/distil-pipeline-executer/dataset"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// datasetJSON encodes the dataset as the json accepted by the HTTP API so
+// it gets parsed and checked the same way.
+func datasetJSON(ds *Dataset) ([]byte, error) {
+	var value interface{}
+	switch {
+	case ds.GetTable() != nil:
+		table := ds.GetTable()
+		rows := make([]dataset.Row, len(table.Rows))
+		for i, r := range table.Rows {
+			data := make(map[string]interface{}, len(r.Data))
+			for k, v := range r.Data {
+				data[k] = v
+			}
+			rows[i] = dataset.Row{ID: r.Id, Data: data}
+		}
+		value = &dataset.Table{ID: table.Id, Columns: table.Columns, Rows: rows}
+	case ds.GetImage() != nil:
+		image := ds.GetImage()
+		images := make([]*dataset.ImageEncoded, len(image.Images))
+		for i, img := range image.Images {
+			images[i] = &dataset.ImageEncoded{
+				ID:    img.Id,
+				Type:  img.Type,
+				Image: base64.StdEncoding.EncodeToString(img.Image),
+				Label: img.Label,
+			}
+		}
+		value = &dataset.Image{ID: image.Id, Images: images}
+	default:
+		return nil, util.NewInvalidError("dataset not provided")
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal dataset")
+	}
+	return data, nil
+}
+
+func toPriority(priority Priority, defaultPriority admission.Priority) admission.Priority {
+	switch priority {
+	case Priority_PRIORITY_BULK:
+		return admission.PriorityBulk
+	case Priority_PRIORITY_INTERACTIVE:
+		return admission.PriorityInteractive
+	default:
+		return defaultPriority
+	}
+}
+
+func toTimestamp(t time.Time) *timestamp.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	ts, err := ptypes.TimestampProto(t)
+	if err != nil {
+		return nil
+	}
+	return ts
+}
+
+func toPipelineInfo(p *task.PipelineInfo) *PipelineInfo {
+	return &PipelineInfo{
+		PipelineId:   p.PipelineID,
+		DatasetId:    p.DatasetID,
+		UploadedTime: toTimestamp(p.UploadedTimestamp),
+		Fitted:       p.Fitted,
+		FittedTime:   toTimestamp(p.FittedTimestamp),
+	}
+}
+
+func toScores(scores []*task.Score) []*Score {
+	converted := make([]*Score, len(scores))
+	for i, s := range scores {
+		converted[i] = &Score{Metric: s.Metric, Value: s.Value}
+	}
+	return converted
+}
+
+func toPredictions(predictions []*task.Prediction) []*Prediction {
+	converted := make([]*Prediction, len(predictions))
+	for i, p := range predictions {
+		converted[i] = &Prediction{
+			Id:            p.ID,
+			Value:         p.Value,
+			Probabilities: p.Probabilities,
+		}
+		converted[i].Confidence = toDoubleValue(p.Confidence)
+	}
+	return converted
+}
+
+// toOutputs converts the outputs listed by name or key, or all of them if
+// none are listed, sorted by key.
+func toOutputs(result *task.ProduceResult, selected []string) []*Output {
+	selection := make(map[string]bool)
+	for _, s := range selected {
+		selection[s] = true
+	}
+
+	outputs := make([]*Output, 0)
+	for key, o := range result.Outputs {
+		if len(selection) > 0 && !selection[key] && !selection[o.Name] {
+			continue
+		}
+		rows := make([]*OutputRow, len(o.Data))
+		for i, r := range o.Data {
+			rows[i] = &OutputRow{Values: r}
+		}
+		outputs = append(outputs, &Output{Key: key, Name: o.Name, Columns: o.Header, Rows: rows})
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		return outputs[i].Key < outputs[j].Key
+	})
+	return outputs
+}
+
+func toFailures(failures []*task.RowFailure) []*RowFailure {
+	converted := make([]*RowFailure, len(failures))
+	for i, f := range failures {
+		converted[i] = &RowFailure{Id: f.ID, Error: f.Error, Traceback: f.Traceback, RunId: f.RunID}
+	}
+	return converted
+}
+
+func toCandidateResult(candidate *task.CandidateResult) *CandidateResult {
+	return &CandidateResult{
+		Metric:          candidate.Metric,
+		Threshold:       candidate.Threshold,
+		HoldoutCount:    int32(candidate.HoldoutCount),
+		CurrentScore:    toDoubleValue(candidate.CurrentScore),
+		CandidateScore:  candidate.CandidateScore,
+		Improvement:     toDoubleValue(candidate.Improvement),
+		Promoted:        candidate.Promoted,
+		CurrentScores:   toScores(candidate.CurrentScores),
+		CandidateScores: toScores(candidate.CandidateScores),
+	}
+}
+
+// toDoubleValue converts the optional value, keeping it unset if missing.
+func toDoubleValue(value *float64) *wrappers.DoubleValue {
+	if value == nil {
+		return nil
+	}
+	return &wrappers.DoubleValue{Value: *value}
+}
@@ -0,0 +1,83 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package rpc
+
+import (
+	"context"
+
+	log "github.com/unchartedsoftware/plog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// toStatus converts the error to a gRPC status using the code matching its
+// kind. Server errors are only explained if verbose.
+func toStatus(err error, verbose bool) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	log.Errorf("%+v", err)
+
+	kind, _ := util.GetErrorKind(err)
+	code := getStatusCode(kind)
+	if code == codes.Internal && !verbose {
+		return status.Error(code, "An error occured on the server while processing the request")
+	}
+	return status.Error(code, err.Error())
+}
+
+func getStatusCode(kind util.ErrorKind) codes.Code {
+	switch kind {
+	case util.KindBadRequest, util.KindInvalid:
+		return codes.InvalidArgument
+	case util.KindNotFound:
+		return codes.NotFound
+	case util.KindConflict:
+		return codes.FailedPrecondition
+	case util.KindUnavailable:
+		return codes.Unavailable
+	case util.KindUnauthorized:
+		return codes.Unauthenticated
+	case util.KindForbidden:
+		return codes.PermissionDenied
+	case util.KindRateLimited:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+// unaryErrors converts the errors returned by unary calls to statuses.
+func unaryErrors(config *env.Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		return res, toStatus(err, config.Reloaded().VerboseError)
+	}
+}
+
+// streamErrors converts the errors returned by streaming calls to statuses.
+func streamErrors(config *env.Config) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return toStatus(handler(srv, stream), config.Reloaded().VerboseError)
+	}
+}
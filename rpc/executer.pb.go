@@ -0,0 +1,1832 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: executer.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Priority is the admission priority of fit and produce requests.
+type Priority int32
+
+const (
+	Priority_PRIORITY_DEFAULT     Priority = 0
+	Priority_PRIORITY_BULK        Priority = 1
+	Priority_PRIORITY_INTERACTIVE Priority = 2
+)
+
+var Priority_name = map[int32]string{
+	0: "PRIORITY_DEFAULT",
+	1: "PRIORITY_BULK",
+	2: "PRIORITY_INTERACTIVE",
+}
+
+var Priority_value = map[string]int32{
+	"PRIORITY_DEFAULT":     0,
+	"PRIORITY_BULK":        1,
+	"PRIORITY_INTERACTIVE": 2,
+}
+
+func (x Priority) String() string {
+	return proto.EnumName(Priority_name, int32(x))
+}
+
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{0}
+}
+
+type ListPipelinesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListPipelinesRequest) Reset()         { *m = ListPipelinesRequest{} }
+func (m *ListPipelinesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListPipelinesRequest) ProtoMessage()    {}
+func (*ListPipelinesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{0}
+}
+
+func (m *ListPipelinesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPipelinesRequest.Unmarshal(m, b)
+}
+func (m *ListPipelinesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPipelinesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListPipelinesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPipelinesRequest.Merge(m, src)
+}
+func (m *ListPipelinesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListPipelinesRequest.Size(m)
+}
+func (m *ListPipelinesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPipelinesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListPipelinesRequest proto.InternalMessageInfo
+
+type ListPipelinesResponse struct {
+	Pipelines            []*PipelineInfo `protobuf:"bytes,1,rep,name=pipelines,proto3" json:"pipelines,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ListPipelinesResponse) Reset()         { *m = ListPipelinesResponse{} }
+func (m *ListPipelinesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListPipelinesResponse) ProtoMessage()    {}
+func (*ListPipelinesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{1}
+}
+
+func (m *ListPipelinesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPipelinesResponse.Unmarshal(m, b)
+}
+func (m *ListPipelinesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPipelinesResponse.Marshal(b, m, deterministic)
+}
+func (m *ListPipelinesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPipelinesResponse.Merge(m, src)
+}
+func (m *ListPipelinesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListPipelinesResponse.Size(m)
+}
+func (m *ListPipelinesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPipelinesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListPipelinesResponse proto.InternalMessageInfo
+
+func (m *ListPipelinesResponse) GetPipelines() []*PipelineInfo {
+	if m != nil {
+		return m.Pipelines
+	}
+	return nil
+}
+
+type PipelineInfo struct {
+	PipelineId           string               `protobuf:"bytes,1,opt,name=pipeline_id,json=pipelineId,proto3" json:"pipeline_id,omitempty"`
+	DatasetId            string               `protobuf:"bytes,2,opt,name=dataset_id,json=datasetId,proto3" json:"dataset_id,omitempty"`
+	UploadedTime         *timestamp.Timestamp `protobuf:"bytes,3,opt,name=uploaded_time,json=uploadedTime,proto3" json:"uploaded_time,omitempty"`
+	Fitted               bool                 `protobuf:"varint,4,opt,name=fitted,proto3" json:"fitted,omitempty"`
+	FittedTime           *timestamp.Timestamp `protobuf:"bytes,5,opt,name=fitted_time,json=fittedTime,proto3" json:"fitted_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PipelineInfo) Reset()         { *m = PipelineInfo{} }
+func (m *PipelineInfo) String() string { return proto.CompactTextString(m) }
+func (*PipelineInfo) ProtoMessage()    {}
+func (*PipelineInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{2}
+}
+
+func (m *PipelineInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PipelineInfo.Unmarshal(m, b)
+}
+func (m *PipelineInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PipelineInfo.Marshal(b, m, deterministic)
+}
+func (m *PipelineInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PipelineInfo.Merge(m, src)
+}
+func (m *PipelineInfo) XXX_Size() int {
+	return xxx_messageInfo_PipelineInfo.Size(m)
+}
+func (m *PipelineInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PipelineInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PipelineInfo proto.InternalMessageInfo
+
+func (m *PipelineInfo) GetPipelineId() string {
+	if m != nil {
+		return m.PipelineId
+	}
+	return ""
+}
+
+func (m *PipelineInfo) GetDatasetId() string {
+	if m != nil {
+		return m.DatasetId
+	}
+	return ""
+}
+
+func (m *PipelineInfo) GetUploadedTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.UploadedTime
+	}
+	return nil
+}
+
+func (m *PipelineInfo) GetFitted() bool {
+	if m != nil {
+		return m.Fitted
+	}
+	return false
+}
+
+func (m *PipelineInfo) GetFittedTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.FittedTime
+	}
+	return nil
+}
+
+// UploadRequest holds the JSON documents describing a pipeline.
+type UploadRequest struct {
+	PipelineId           string   `protobuf:"bytes,1,opt,name=pipeline_id,json=pipelineId,proto3" json:"pipeline_id,omitempty"`
+	DatasetSchema        []byte   `protobuf:"bytes,2,opt,name=dataset_schema,json=datasetSchema,proto3" json:"dataset_schema,omitempty"`
+	Pipeline             []byte   `protobuf:"bytes,3,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	Problem              []byte   `protobuf:"bytes,4,opt,name=problem,proto3" json:"problem,omitempty"`
+	KeepExisting         bool     `protobuf:"varint,5,opt,name=keep_existing,json=keepExisting,proto3" json:"keep_existing,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UploadRequest) Reset()         { *m = UploadRequest{} }
+func (m *UploadRequest) String() string { return proto.CompactTextString(m) }
+func (*UploadRequest) ProtoMessage()    {}
+func (*UploadRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{3}
+}
+
+func (m *UploadRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UploadRequest.Unmarshal(m, b)
+}
+func (m *UploadRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UploadRequest.Marshal(b, m, deterministic)
+}
+func (m *UploadRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UploadRequest.Merge(m, src)
+}
+func (m *UploadRequest) XXX_Size() int {
+	return xxx_messageInfo_UploadRequest.Size(m)
+}
+func (m *UploadRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UploadRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UploadRequest proto.InternalMessageInfo
+
+func (m *UploadRequest) GetPipelineId() string {
+	if m != nil {
+		return m.PipelineId
+	}
+	return ""
+}
+
+func (m *UploadRequest) GetDatasetSchema() []byte {
+	if m != nil {
+		return m.DatasetSchema
+	}
+	return nil
+}
+
+func (m *UploadRequest) GetPipeline() []byte {
+	if m != nil {
+		return m.Pipeline
+	}
+	return nil
+}
+
+func (m *UploadRequest) GetProblem() []byte {
+	if m != nil {
+		return m.Problem
+	}
+	return nil
+}
+
+func (m *UploadRequest) GetKeepExisting() bool {
+	if m != nil {
+		return m.KeepExisting
+	}
+	return false
+}
+
+// UploadFittedRequest is a chunk of a fitted pipeline. The pipeline id only
+// needs to be set on the first chunk.
+type UploadFittedRequest struct {
+	PipelineId           string   `protobuf:"bytes,1,opt,name=pipeline_id,json=pipelineId,proto3" json:"pipeline_id,omitempty"`
+	Chunk                []byte   `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UploadFittedRequest) Reset()         { *m = UploadFittedRequest{} }
+func (m *UploadFittedRequest) String() string { return proto.CompactTextString(m) }
+func (*UploadFittedRequest) ProtoMessage()    {}
+func (*UploadFittedRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{4}
+}
+
+func (m *UploadFittedRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UploadFittedRequest.Unmarshal(m, b)
+}
+func (m *UploadFittedRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UploadFittedRequest.Marshal(b, m, deterministic)
+}
+func (m *UploadFittedRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UploadFittedRequest.Merge(m, src)
+}
+func (m *UploadFittedRequest) XXX_Size() int {
+	return xxx_messageInfo_UploadFittedRequest.Size(m)
+}
+func (m *UploadFittedRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UploadFittedRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UploadFittedRequest proto.InternalMessageInfo
+
+func (m *UploadFittedRequest) GetPipelineId() string {
+	if m != nil {
+		return m.PipelineId
+	}
+	return ""
+}
+
+func (m *UploadFittedRequest) GetChunk() []byte {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+type UploadResponse struct {
+	PipelineId           string   `protobuf:"bytes,1,opt,name=pipeline_id,json=pipelineId,proto3" json:"pipeline_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UploadResponse) Reset()         { *m = UploadResponse{} }
+func (m *UploadResponse) String() string { return proto.CompactTextString(m) }
+func (*UploadResponse) ProtoMessage()    {}
+func (*UploadResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{5}
+}
+
+func (m *UploadResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UploadResponse.Unmarshal(m, b)
+}
+func (m *UploadResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UploadResponse.Marshal(b, m, deterministic)
+}
+func (m *UploadResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UploadResponse.Merge(m, src)
+}
+func (m *UploadResponse) XXX_Size() int {
+	return xxx_messageInfo_UploadResponse.Size(m)
+}
+func (m *UploadResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UploadResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UploadResponse proto.InternalMessageInfo
+
+func (m *UploadResponse) GetPipelineId() string {
+	if m != nil {
+		return m.PipelineId
+	}
+	return ""
+}
+
+// Dataset is a table or image dataset, matching the dataset of the pipeline.
+type Dataset struct {
+	// Types that are valid to be assigned to Data:
+	//	*Dataset_Table
+	//	*Dataset_Image
+	Data                 isDataset_Data `protobuf_oneof:"data"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Dataset) Reset()         { *m = Dataset{} }
+func (m *Dataset) String() string { return proto.CompactTextString(m) }
+func (*Dataset) ProtoMessage()    {}
+func (*Dataset) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{6}
+}
+
+func (m *Dataset) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Dataset.Unmarshal(m, b)
+}
+func (m *Dataset) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Dataset.Marshal(b, m, deterministic)
+}
+func (m *Dataset) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Dataset.Merge(m, src)
+}
+func (m *Dataset) XXX_Size() int {
+	return xxx_messageInfo_Dataset.Size(m)
+}
+func (m *Dataset) XXX_DiscardUnknown() {
+	xxx_messageInfo_Dataset.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Dataset proto.InternalMessageInfo
+
+type isDataset_Data interface {
+	isDataset_Data()
+}
+
+type Dataset_Table struct {
+	Table *Table `protobuf:"bytes,1,opt,name=table,proto3,oneof"`
+}
+
+type Dataset_Image struct {
+	Image *Image `protobuf:"bytes,2,opt,name=image,proto3,oneof"`
+}
+
+func (*Dataset_Table) isDataset_Data() {}
+
+func (*Dataset_Image) isDataset_Data() {}
+
+func (m *Dataset) GetData() isDataset_Data {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Dataset) GetTable() *Table {
+	if x, ok := m.GetData().(*Dataset_Table); ok {
+		return x.Table
+	}
+	return nil
+}
+
+func (m *Dataset) GetImage() *Image {
+	if x, ok := m.GetData().(*Dataset_Image); ok {
+		return x.Image
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Dataset) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Dataset_Table)(nil),
+		(*Dataset_Image)(nil),
+	}
+}
+
+// Table is a table dataset. Columns is optional and, when provided, fixes
+// the order of the columns.
+type Table struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Columns              []string `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	Rows                 []*Row   `protobuf:"bytes,3,rep,name=rows,proto3" json:"rows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Table) Reset()         { *m = Table{} }
+func (m *Table) String() string { return proto.CompactTextString(m) }
+func (*Table) ProtoMessage()    {}
+func (*Table) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{7}
+}
+
+func (m *Table) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Table.Unmarshal(m, b)
+}
+func (m *Table) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Table.Marshal(b, m, deterministic)
+}
+func (m *Table) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Table.Merge(m, src)
+}
+func (m *Table) XXX_Size() int {
+	return xxx_messageInfo_Table.Size(m)
+}
+func (m *Table) XXX_DiscardUnknown() {
+	xxx_messageInfo_Table.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Table proto.InternalMessageInfo
+
+func (m *Table) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Table) GetColumns() []string {
+	if m != nil {
+		return m.Columns
+	}
+	return nil
+}
+
+func (m *Table) GetRows() []*Row {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+// Row is a row of table data. Missing fields are treated as missing values.
+type Row struct {
+	Id                   string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Data                 map[string]string `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Row) Reset()         { *m = Row{} }
+func (m *Row) String() string { return proto.CompactTextString(m) }
+func (*Row) ProtoMessage()    {}
+func (*Row) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{8}
+}
+
+func (m *Row) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Row.Unmarshal(m, b)
+}
+func (m *Row) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Row.Marshal(b, m, deterministic)
+}
+func (m *Row) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Row.Merge(m, src)
+}
+func (m *Row) XXX_Size() int {
+	return xxx_messageInfo_Row.Size(m)
+}
+func (m *Row) XXX_DiscardUnknown() {
+	xxx_messageInfo_Row.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Row proto.InternalMessageInfo
+
+func (m *Row) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Row) GetData() map[string]string {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// Image is an image dataset.
+type Image struct {
+	Id                   string          `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Images               []*EncodedImage `protobuf:"bytes,2,rep,name=images,proto3" json:"images,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Image) Reset()         { *m = Image{} }
+func (m *Image) String() string { return proto.CompactTextString(m) }
+func (*Image) ProtoMessage()    {}
+func (*Image) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{9}
+}
+
+func (m *Image) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Image.Unmarshal(m, b)
+}
+func (m *Image) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Image.Marshal(b, m, deterministic)
+}
+func (m *Image) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Image.Merge(m, src)
+}
+func (m *Image) XXX_Size() int {
+	return xxx_messageInfo_Image.Size(m)
+}
+func (m *Image) XXX_DiscardUnknown() {
+	xxx_messageInfo_Image.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Image proto.InternalMessageInfo
+
+func (m *Image) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Image) GetImages() []*EncodedImage {
+	if m != nil {
+		return m.Images
+	}
+	return nil
+}
+
+// EncodedImage is an image in the format given by its type, ie: png.
+type EncodedImage struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Image                []byte   `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	Label                string   `protobuf:"bytes,4,opt,name=label,proto3" json:"label,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EncodedImage) Reset()         { *m = EncodedImage{} }
+func (m *EncodedImage) String() string { return proto.CompactTextString(m) }
+func (*EncodedImage) ProtoMessage()    {}
+func (*EncodedImage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{10}
+}
+
+func (m *EncodedImage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EncodedImage.Unmarshal(m, b)
+}
+func (m *EncodedImage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EncodedImage.Marshal(b, m, deterministic)
+}
+func (m *EncodedImage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EncodedImage.Merge(m, src)
+}
+func (m *EncodedImage) XXX_Size() int {
+	return xxx_messageInfo_EncodedImage.Size(m)
+}
+func (m *EncodedImage) XXX_DiscardUnknown() {
+	xxx_messageInfo_EncodedImage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EncodedImage proto.InternalMessageInfo
+
+func (m *EncodedImage) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *EncodedImage) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *EncodedImage) GetImage() []byte {
+	if m != nil {
+		return m.Image
+	}
+	return nil
+}
+
+func (m *EncodedImage) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+type FitRequest struct {
+	PipelineId string   `protobuf:"bytes,1,opt,name=pipeline_id,json=pipelineId,proto3" json:"pipeline_id,omitempty"`
+	Dataset    *Dataset `protobuf:"bytes,2,opt,name=dataset,proto3" json:"dataset,omitempty"`
+	Priority   Priority `protobuf:"varint,3,opt,name=priority,proto3,enum=executer.Priority" json:"priority,omitempty"`
+	// validation is the validation method, either holdout or kfold.
+	Validation   string  `protobuf:"bytes,4,opt,name=validation,proto3" json:"validation,omitempty"`
+	HoldoutRatio float64 `protobuf:"fixed64,5,opt,name=holdout_ratio,json=holdoutRatio,proto3" json:"holdout_ratio,omitempty"`
+	Folds        int32   `protobuf:"varint,6,opt,name=folds,proto3" json:"folds,omitempty"`
+	Seed         int64   `protobuf:"varint,7,opt,name=seed,proto3" json:"seed,omitempty"`
+	// training accumulates the training data, either append or replace.
+	Training string `protobuf:"bytes,8,opt,name=training,proto3" json:"training,omitempty"`
+	// candidate fits a candidate pipeline only promoted if it scores better
+	// on held out data than the fitted one.
+	Candidate bool `protobuf:"varint,9,opt,name=candidate,proto3" json:"candidate,omitempty"`
+	// metric and threshold override the configured promotion settings.
+	Metric               string                `protobuf:"bytes,10,opt,name=metric,proto3" json:"metric,omitempty"`
+	Threshold            *wrappers.DoubleValue `protobuf:"bytes,11,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FitRequest) Reset()         { *m = FitRequest{} }
+func (m *FitRequest) String() string { return proto.CompactTextString(m) }
+func (*FitRequest) ProtoMessage()    {}
+func (*FitRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{11}
+}
+
+func (m *FitRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FitRequest.Unmarshal(m, b)
+}
+func (m *FitRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FitRequest.Marshal(b, m, deterministic)
+}
+func (m *FitRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FitRequest.Merge(m, src)
+}
+func (m *FitRequest) XXX_Size() int {
+	return xxx_messageInfo_FitRequest.Size(m)
+}
+func (m *FitRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FitRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FitRequest proto.InternalMessageInfo
+
+func (m *FitRequest) GetPipelineId() string {
+	if m != nil {
+		return m.PipelineId
+	}
+	return ""
+}
+
+func (m *FitRequest) GetDataset() *Dataset {
+	if m != nil {
+		return m.Dataset
+	}
+	return nil
+}
+
+func (m *FitRequest) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_PRIORITY_DEFAULT
+}
+
+func (m *FitRequest) GetValidation() string {
+	if m != nil {
+		return m.Validation
+	}
+	return ""
+}
+
+func (m *FitRequest) GetHoldoutRatio() float64 {
+	if m != nil {
+		return m.HoldoutRatio
+	}
+	return 0
+}
+
+func (m *FitRequest) GetFolds() int32 {
+	if m != nil {
+		return m.Folds
+	}
+	return 0
+}
+
+func (m *FitRequest) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+func (m *FitRequest) GetTraining() string {
+	if m != nil {
+		return m.Training
+	}
+	return ""
+}
+
+func (m *FitRequest) GetCandidate() bool {
+	if m != nil {
+		return m.Candidate
+	}
+	return false
+}
+
+func (m *FitRequest) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *FitRequest) GetThreshold() *wrappers.DoubleValue {
+	if m != nil {
+		return m.Threshold
+	}
+	return nil
+}
+
+type FitResponse struct {
+	PipelineId           string           `protobuf:"bytes,1,opt,name=pipeline_id,json=pipelineId,proto3" json:"pipeline_id,omitempty"`
+	PredictionId         string           `protobuf:"bytes,2,opt,name=prediction_id,json=predictionId,proto3" json:"prediction_id,omitempty"`
+	RunId                string           `protobuf:"bytes,3,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	TrainingRows         int32            `protobuf:"varint,4,opt,name=training_rows,json=trainingRows,proto3" json:"training_rows,omitempty"`
+	ValidationScores     []*Score         `protobuf:"bytes,5,rep,name=validation_scores,json=validationScores,proto3" json:"validation_scores,omitempty"`
+	JobId                string           `protobuf:"bytes,6,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Fitted               bool             `protobuf:"varint,7,opt,name=fitted,proto3" json:"fitted,omitempty"`
+	Candidate            *CandidateResult `protobuf:"bytes,8,opt,name=candidate,proto3" json:"candidate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *FitResponse) Reset()         { *m = FitResponse{} }
+func (m *FitResponse) String() string { return proto.CompactTextString(m) }
+func (*FitResponse) ProtoMessage()    {}
+func (*FitResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{12}
+}
+
+func (m *FitResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FitResponse.Unmarshal(m, b)
+}
+func (m *FitResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FitResponse.Marshal(b, m, deterministic)
+}
+func (m *FitResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FitResponse.Merge(m, src)
+}
+func (m *FitResponse) XXX_Size() int {
+	return xxx_messageInfo_FitResponse.Size(m)
+}
+func (m *FitResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FitResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FitResponse proto.InternalMessageInfo
+
+func (m *FitResponse) GetPipelineId() string {
+	if m != nil {
+		return m.PipelineId
+	}
+	return ""
+}
+
+func (m *FitResponse) GetPredictionId() string {
+	if m != nil {
+		return m.PredictionId
+	}
+	return ""
+}
+
+func (m *FitResponse) GetRunId() string {
+	if m != nil {
+		return m.RunId
+	}
+	return ""
+}
+
+func (m *FitResponse) GetTrainingRows() int32 {
+	if m != nil {
+		return m.TrainingRows
+	}
+	return 0
+}
+
+func (m *FitResponse) GetValidationScores() []*Score {
+	if m != nil {
+		return m.ValidationScores
+	}
+	return nil
+}
+
+func (m *FitResponse) GetJobId() string {
+	if m != nil {
+		return m.JobId
+	}
+	return ""
+}
+
+func (m *FitResponse) GetFitted() bool {
+	if m != nil {
+		return m.Fitted
+	}
+	return false
+}
+
+func (m *FitResponse) GetCandidate() *CandidateResult {
+	if m != nil {
+		return m.Candidate
+	}
+	return nil
+}
+
+type CandidateResult struct {
+	Metric       string  `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`
+	Threshold    float64 `protobuf:"fixed64,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	HoldoutCount int32   `protobuf:"varint,3,opt,name=holdout_count,json=holdoutCount,proto3" json:"holdout_count,omitempty"`
+	// current_score is missing if no pipeline was fitted yet.
+	CurrentScore         *wrappers.DoubleValue `protobuf:"bytes,4,opt,name=current_score,json=currentScore,proto3" json:"current_score,omitempty"`
+	CandidateScore       float64               `protobuf:"fixed64,5,opt,name=candidate_score,json=candidateScore,proto3" json:"candidate_score,omitempty"`
+	Improvement          *wrappers.DoubleValue `protobuf:"bytes,6,opt,name=improvement,proto3" json:"improvement,omitempty"`
+	Promoted             bool                  `protobuf:"varint,7,opt,name=promoted,proto3" json:"promoted,omitempty"`
+	CurrentScores        []*Score              `protobuf:"bytes,8,rep,name=current_scores,json=currentScores,proto3" json:"current_scores,omitempty"`
+	CandidateScores      []*Score              `protobuf:"bytes,9,rep,name=candidate_scores,json=candidateScores,proto3" json:"candidate_scores,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *CandidateResult) Reset()         { *m = CandidateResult{} }
+func (m *CandidateResult) String() string { return proto.CompactTextString(m) }
+func (*CandidateResult) ProtoMessage()    {}
+func (*CandidateResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{13}
+}
+
+func (m *CandidateResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CandidateResult.Unmarshal(m, b)
+}
+func (m *CandidateResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CandidateResult.Marshal(b, m, deterministic)
+}
+func (m *CandidateResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CandidateResult.Merge(m, src)
+}
+func (m *CandidateResult) XXX_Size() int {
+	return xxx_messageInfo_CandidateResult.Size(m)
+}
+func (m *CandidateResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_CandidateResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CandidateResult proto.InternalMessageInfo
+
+func (m *CandidateResult) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *CandidateResult) GetThreshold() float64 {
+	if m != nil {
+		return m.Threshold
+	}
+	return 0
+}
+
+func (m *CandidateResult) GetHoldoutCount() int32 {
+	if m != nil {
+		return m.HoldoutCount
+	}
+	return 0
+}
+
+func (m *CandidateResult) GetCurrentScore() *wrappers.DoubleValue {
+	if m != nil {
+		return m.CurrentScore
+	}
+	return nil
+}
+
+func (m *CandidateResult) GetCandidateScore() float64 {
+	if m != nil {
+		return m.CandidateScore
+	}
+	return 0
+}
+
+func (m *CandidateResult) GetImprovement() *wrappers.DoubleValue {
+	if m != nil {
+		return m.Improvement
+	}
+	return nil
+}
+
+func (m *CandidateResult) GetPromoted() bool {
+	if m != nil {
+		return m.Promoted
+	}
+	return false
+}
+
+func (m *CandidateResult) GetCurrentScores() []*Score {
+	if m != nil {
+		return m.CurrentScores
+	}
+	return nil
+}
+
+func (m *CandidateResult) GetCandidateScores() []*Score {
+	if m != nil {
+		return m.CandidateScores
+	}
+	return nil
+}
+
+type Score struct {
+	Metric               string   `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`
+	Value                float64  `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Score) Reset()         { *m = Score{} }
+func (m *Score) String() string { return proto.CompactTextString(m) }
+func (*Score) ProtoMessage()    {}
+func (*Score) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{14}
+}
+
+func (m *Score) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Score.Unmarshal(m, b)
+}
+func (m *Score) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Score.Marshal(b, m, deterministic)
+}
+func (m *Score) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Score.Merge(m, src)
+}
+func (m *Score) XXX_Size() int {
+	return xxx_messageInfo_Score.Size(m)
+}
+func (m *Score) XXX_DiscardUnknown() {
+	xxx_messageInfo_Score.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Score proto.InternalMessageInfo
+
+func (m *Score) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *Score) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type ProduceRequest struct {
+	PipelineId string   `protobuf:"bytes,1,opt,name=pipeline_id,json=pipelineId,proto3" json:"pipeline_id,omitempty"`
+	Dataset    *Dataset `protobuf:"bytes,2,opt,name=dataset,proto3" json:"dataset,omitempty"`
+	Priority   Priority `protobuf:"varint,3,opt,name=priority,proto3,enum=executer.Priority" json:"priority,omitempty"`
+	// outputs lists the pipeline outputs to return by name or key, all by
+	// default.
+	Outputs              []string `protobuf:"bytes,4,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProduceRequest) Reset()         { *m = ProduceRequest{} }
+func (m *ProduceRequest) String() string { return proto.CompactTextString(m) }
+func (*ProduceRequest) ProtoMessage()    {}
+func (*ProduceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{15}
+}
+
+func (m *ProduceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProduceRequest.Unmarshal(m, b)
+}
+func (m *ProduceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProduceRequest.Marshal(b, m, deterministic)
+}
+func (m *ProduceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProduceRequest.Merge(m, src)
+}
+func (m *ProduceRequest) XXX_Size() int {
+	return xxx_messageInfo_ProduceRequest.Size(m)
+}
+func (m *ProduceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProduceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProduceRequest proto.InternalMessageInfo
+
+func (m *ProduceRequest) GetPipelineId() string {
+	if m != nil {
+		return m.PipelineId
+	}
+	return ""
+}
+
+func (m *ProduceRequest) GetDataset() *Dataset {
+	if m != nil {
+		return m.Dataset
+	}
+	return nil
+}
+
+func (m *ProduceRequest) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_PRIORITY_DEFAULT
+}
+
+func (m *ProduceRequest) GetOutputs() []string {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+type ProduceResponse struct {
+	PipelineId           string        `protobuf:"bytes,1,opt,name=pipeline_id,json=pipelineId,proto3" json:"pipeline_id,omitempty"`
+	PredictionId         string        `protobuf:"bytes,2,opt,name=prediction_id,json=predictionId,proto3" json:"prediction_id,omitempty"`
+	Predictions          []*Prediction `protobuf:"bytes,3,rep,name=predictions,proto3" json:"predictions,omitempty"`
+	Outputs              []*Output     `protobuf:"bytes,4,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	RunIds               []string      `protobuf:"bytes,5,rep,name=run_ids,json=runIds,proto3" json:"run_ids,omitempty"`
+	Failures             []*RowFailure `protobuf:"bytes,6,rep,name=failures,proto3" json:"failures,omitempty"`
+	JobId                string        `protobuf:"bytes,7,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ProduceResponse) Reset()         { *m = ProduceResponse{} }
+func (m *ProduceResponse) String() string { return proto.CompactTextString(m) }
+func (*ProduceResponse) ProtoMessage()    {}
+func (*ProduceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{16}
+}
+
+func (m *ProduceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProduceResponse.Unmarshal(m, b)
+}
+func (m *ProduceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProduceResponse.Marshal(b, m, deterministic)
+}
+func (m *ProduceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProduceResponse.Merge(m, src)
+}
+func (m *ProduceResponse) XXX_Size() int {
+	return xxx_messageInfo_ProduceResponse.Size(m)
+}
+func (m *ProduceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProduceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProduceResponse proto.InternalMessageInfo
+
+func (m *ProduceResponse) GetPipelineId() string {
+	if m != nil {
+		return m.PipelineId
+	}
+	return ""
+}
+
+func (m *ProduceResponse) GetPredictionId() string {
+	if m != nil {
+		return m.PredictionId
+	}
+	return ""
+}
+
+func (m *ProduceResponse) GetPredictions() []*Prediction {
+	if m != nil {
+		return m.Predictions
+	}
+	return nil
+}
+
+func (m *ProduceResponse) GetOutputs() []*Output {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *ProduceResponse) GetRunIds() []string {
+	if m != nil {
+		return m.RunIds
+	}
+	return nil
+}
+
+func (m *ProduceResponse) GetFailures() []*RowFailure {
+	if m != nil {
+		return m.Failures
+	}
+	return nil
+}
+
+func (m *ProduceResponse) GetJobId() string {
+	if m != nil {
+		return m.JobId
+	}
+	return ""
+}
+
+type Prediction struct {
+	Id                   string                `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Value                string                `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Confidence           *wrappers.DoubleValue `protobuf:"bytes,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Probabilities        map[string]float64    `protobuf:"bytes,4,rep,name=probabilities,proto3" json:"probabilities,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *Prediction) Reset()         { *m = Prediction{} }
+func (m *Prediction) String() string { return proto.CompactTextString(m) }
+func (*Prediction) ProtoMessage()    {}
+func (*Prediction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{17}
+}
+
+func (m *Prediction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Prediction.Unmarshal(m, b)
+}
+func (m *Prediction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Prediction.Marshal(b, m, deterministic)
+}
+func (m *Prediction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Prediction.Merge(m, src)
+}
+func (m *Prediction) XXX_Size() int {
+	return xxx_messageInfo_Prediction.Size(m)
+}
+func (m *Prediction) XXX_DiscardUnknown() {
+	xxx_messageInfo_Prediction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Prediction proto.InternalMessageInfo
+
+func (m *Prediction) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Prediction) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *Prediction) GetConfidence() *wrappers.DoubleValue {
+	if m != nil {
+		return m.Confidence
+	}
+	return nil
+}
+
+func (m *Prediction) GetProbabilities() map[string]float64 {
+	if m != nil {
+		return m.Probabilities
+	}
+	return nil
+}
+
+type Output struct {
+	Key                  string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Name                 string       `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Columns              []string     `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty"`
+	Rows                 []*OutputRow `protobuf:"bytes,4,rep,name=rows,proto3" json:"rows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Output) Reset()         { *m = Output{} }
+func (m *Output) String() string { return proto.CompactTextString(m) }
+func (*Output) ProtoMessage()    {}
+func (*Output) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{18}
+}
+
+func (m *Output) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Output.Unmarshal(m, b)
+}
+func (m *Output) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Output.Marshal(b, m, deterministic)
+}
+func (m *Output) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Output.Merge(m, src)
+}
+func (m *Output) XXX_Size() int {
+	return xxx_messageInfo_Output.Size(m)
+}
+func (m *Output) XXX_DiscardUnknown() {
+	xxx_messageInfo_Output.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Output proto.InternalMessageInfo
+
+func (m *Output) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Output) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Output) GetColumns() []string {
+	if m != nil {
+		return m.Columns
+	}
+	return nil
+}
+
+func (m *Output) GetRows() []*OutputRow {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+type OutputRow struct {
+	Values               []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OutputRow) Reset()         { *m = OutputRow{} }
+func (m *OutputRow) String() string { return proto.CompactTextString(m) }
+func (*OutputRow) ProtoMessage()    {}
+func (*OutputRow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{19}
+}
+
+func (m *OutputRow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OutputRow.Unmarshal(m, b)
+}
+func (m *OutputRow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OutputRow.Marshal(b, m, deterministic)
+}
+func (m *OutputRow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OutputRow.Merge(m, src)
+}
+func (m *OutputRow) XXX_Size() int {
+	return xxx_messageInfo_OutputRow.Size(m)
+}
+func (m *OutputRow) XXX_DiscardUnknown() {
+	xxx_messageInfo_OutputRow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OutputRow proto.InternalMessageInfo
+
+func (m *OutputRow) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type RowFailure struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Traceback            string   `protobuf:"bytes,3,opt,name=traceback,proto3" json:"traceback,omitempty"`
+	RunId                string   `protobuf:"bytes,4,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RowFailure) Reset()         { *m = RowFailure{} }
+func (m *RowFailure) String() string { return proto.CompactTextString(m) }
+func (*RowFailure) ProtoMessage()    {}
+func (*RowFailure) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1351dcdb055403f5, []int{20}
+}
+
+func (m *RowFailure) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RowFailure.Unmarshal(m, b)
+}
+func (m *RowFailure) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RowFailure.Marshal(b, m, deterministic)
+}
+func (m *RowFailure) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RowFailure.Merge(m, src)
+}
+func (m *RowFailure) XXX_Size() int {
+	return xxx_messageInfo_RowFailure.Size(m)
+}
+func (m *RowFailure) XXX_DiscardUnknown() {
+	xxx_messageInfo_RowFailure.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RowFailure proto.InternalMessageInfo
+
+func (m *RowFailure) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RowFailure) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *RowFailure) GetTraceback() string {
+	if m != nil {
+		return m.Traceback
+	}
+	return ""
+}
+
+func (m *RowFailure) GetRunId() string {
+	if m != nil {
+		return m.RunId
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterEnum("executer.Priority", Priority_name, Priority_value)
+	proto.RegisterType((*ListPipelinesRequest)(nil), "executer.ListPipelinesRequest")
+	proto.RegisterType((*ListPipelinesResponse)(nil), "executer.ListPipelinesResponse")
+	proto.RegisterType((*PipelineInfo)(nil), "executer.PipelineInfo")
+	proto.RegisterType((*UploadRequest)(nil), "executer.UploadRequest")
+	proto.RegisterType((*UploadFittedRequest)(nil), "executer.UploadFittedRequest")
+	proto.RegisterType((*UploadResponse)(nil), "executer.UploadResponse")
+	proto.RegisterType((*Dataset)(nil), "executer.Dataset")
+	proto.RegisterType((*Table)(nil), "executer.Table")
+	proto.RegisterType((*Row)(nil), "executer.Row")
+	proto.RegisterMapType((map[string]string)(nil), "executer.Row.DataEntry")
+	proto.RegisterType((*Image)(nil), "executer.Image")
+	proto.RegisterType((*EncodedImage)(nil), "executer.EncodedImage")
+	proto.RegisterType((*FitRequest)(nil), "executer.FitRequest")
+	proto.RegisterType((*FitResponse)(nil), "executer.FitResponse")
+	proto.RegisterType((*CandidateResult)(nil), "executer.CandidateResult")
+	proto.RegisterType((*Score)(nil), "executer.Score")
+	proto.RegisterType((*ProduceRequest)(nil), "executer.ProduceRequest")
+	proto.RegisterType((*ProduceResponse)(nil), "executer.ProduceResponse")
+	proto.RegisterType((*Prediction)(nil), "executer.Prediction")
+	proto.RegisterMapType((map[string]float64)(nil), "executer.Prediction.ProbabilitiesEntry")
+	proto.RegisterType((*Output)(nil), "executer.Output")
+	proto.RegisterType((*OutputRow)(nil), "executer.OutputRow")
+	proto.RegisterType((*RowFailure)(nil), "executer.RowFailure")
+}
+
+func init() { proto.RegisterFile("executer.proto", fileDescriptor_1351dcdb055403f5) }
+
+var fileDescriptor_1351dcdb055403f5 = []byte{
+	// 1445 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x57, 0x4b, 0x73, 0x13, 0xc7,
+	0x16, 0x66, 0xf4, 0xd6, 0xd1, 0xc3, 0xa2, 0x31, 0x30, 0xa8, 0x78, 0xe8, 0x0e, 0x75, 0xcb, 0x2a,
+	0x28, 0x64, 0xae, 0x6e, 0x02, 0x94, 0xa1, 0x92, 0x60, 0xb0, 0x13, 0x55, 0x4c, 0x70, 0x35, 0x82,
+	0xaa, 0x64, 0x11, 0xd5, 0x68, 0xa6, 0x6d, 0x0f, 0x1e, 0x4d, 0x0f, 0x3d, 0x3d, 0x18, 0x57, 0x56,
+	0x59, 0xe5, 0x57, 0x64, 0xcf, 0x3a, 0x55, 0xf9, 0x37, 0x59, 0xe6, 0x87, 0xa4, 0xfa, 0x31, 0x0f,
+	0x3d, 0x88, 0xbd, 0xc8, 0x22, 0x2b, 0xcd, 0xf9, 0xce, 0xe9, 0x3e, 0xef, 0xd3, 0x47, 0xd0, 0x26,
+	0x1f, 0x88, 0x13, 0x73, 0xc2, 0x06, 0x21, 0xa3, 0x9c, 0xa2, 0x5a, 0x42, 0x77, 0x6f, 0x1d, 0x52,
+	0x7a, 0xe8, 0x93, 0x4d, 0x89, 0x4f, 0xe3, 0x83, 0x4d, 0xee, 0xcd, 0x48, 0xc4, 0xed, 0x59, 0xa8,
+	0x44, 0xbb, 0x37, 0x17, 0x05, 0x4e, 0x98, 0x1d, 0x86, 0x84, 0x45, 0x8a, 0x6f, 0x5d, 0x81, 0xf5,
+	0x3d, 0x2f, 0xe2, 0xfb, 0x5e, 0x48, 0x7c, 0x2f, 0x20, 0x11, 0x26, 0xef, 0x62, 0x12, 0x71, 0xeb,
+	0x05, 0x5c, 0x5e, 0xc0, 0xa3, 0x90, 0x06, 0x11, 0x41, 0x9f, 0x41, 0x3d, 0x4c, 0x40, 0xd3, 0xe8,
+	0x15, 0xfb, 0x8d, 0xe1, 0x95, 0x41, 0x6a, 0x5f, 0x22, 0x3f, 0x0a, 0x0e, 0x28, 0xce, 0x04, 0xad,
+	0x3f, 0x0d, 0x68, 0xe6, 0x79, 0xe8, 0x16, 0x34, 0x12, 0xee, 0xc4, 0x73, 0x4d, 0xa3, 0x67, 0xf4,
+	0xeb, 0x18, 0x12, 0x68, 0xe4, 0xa2, 0x1b, 0x00, 0xae, 0xcd, 0xed, 0x88, 0x70, 0xc1, 0x2f, 0x48,
+	0x7e, 0x5d, 0x23, 0x23, 0x17, 0x7d, 0x09, 0xad, 0x38, 0xf4, 0xa9, 0xed, 0x12, 0x77, 0x22, 0x7c,
+	0x36, 0x8b, 0x3d, 0xa3, 0xdf, 0x18, 0x76, 0x07, 0xca, 0xdf, 0x41, 0xe2, 0xef, 0x60, 0x9c, 0x04,
+	0x04, 0x37, 0x93, 0x03, 0x02, 0x42, 0x57, 0xa0, 0x72, 0xe0, 0x71, 0x4e, 0x5c, 0xb3, 0xd4, 0x33,
+	0xfa, 0x35, 0xac, 0x29, 0xf4, 0x18, 0x1a, 0xea, 0x4b, 0x5d, 0x5b, 0x3e, 0xf3, 0x5a, 0x50, 0xe2,
+	0x02, 0xb0, 0x7e, 0x33, 0xa0, 0xf5, 0x5a, 0x6a, 0xd1, 0x71, 0x3c, 0xdb, 0xcf, 0xff, 0x42, 0x3b,
+	0xf1, 0x33, 0x72, 0x8e, 0xc8, 0xcc, 0x96, 0xbe, 0x36, 0x71, 0x4b, 0xa3, 0xaf, 0x24, 0x88, 0xba,
+	0x50, 0x4b, 0x0e, 0x49, 0x57, 0x9b, 0x38, 0xa5, 0x91, 0x09, 0xd5, 0x90, 0xd1, 0xa9, 0x4f, 0x66,
+	0xd2, 0x97, 0x26, 0x4e, 0x48, 0x74, 0x1b, 0x5a, 0xc7, 0x84, 0x84, 0x13, 0xf2, 0xc1, 0x8b, 0xb8,
+	0x17, 0x1c, 0x4a, 0x77, 0x6a, 0xb8, 0x29, 0xc0, 0x1d, 0x8d, 0x59, 0x7b, 0x70, 0x49, 0xd9, 0xbc,
+	0x2b, 0x1d, 0x39, 0xb7, 0xe5, 0xeb, 0x50, 0x76, 0x8e, 0xe2, 0xe0, 0x58, 0x1b, 0xac, 0x08, 0xeb,
+	0x7f, 0xd0, 0x4e, 0x22, 0xa0, 0x2b, 0xe6, 0xac, 0x8b, 0x2c, 0x07, 0xaa, 0xcf, 0x95, 0xb3, 0x68,
+	0x03, 0xca, 0xdc, 0x9e, 0xfa, 0x44, 0x4a, 0x35, 0x86, 0x6b, 0x59, 0x65, 0x8d, 0x05, 0xfc, 0xcd,
+	0x05, 0xac, 0xf8, 0x42, 0xd0, 0x9b, 0xd9, 0x87, 0xc4, 0x2c, 0x2c, 0x0a, 0x8e, 0x04, 0x2c, 0x04,
+	0x25, 0x7f, 0xbb, 0x02, 0x25, 0x11, 0x49, 0x6b, 0x0c, 0x65, 0x79, 0x05, 0x6a, 0x43, 0x21, 0xb5,
+	0xa2, 0xe0, 0xb9, 0x22, 0x7a, 0x0e, 0xf5, 0xe3, 0x59, 0x10, 0x99, 0x85, 0x5e, 0xb1, 0x5f, 0xc7,
+	0x09, 0x89, 0xfe, 0x03, 0x25, 0x46, 0x4f, 0x22, 0xb3, 0x28, 0xab, 0xbc, 0x95, 0xa9, 0xc0, 0xf4,
+	0x04, 0x4b, 0x96, 0xf5, 0x13, 0x14, 0x31, 0x3d, 0x59, 0xba, 0xf3, 0xae, 0x52, 0x2a, 0x2f, 0x6c,
+	0x0c, 0xaf, 0xce, 0x9d, 0x1c, 0x08, 0x5f, 0x77, 0x02, 0xce, 0x4e, 0xb1, 0x14, 0xea, 0x3e, 0x84,
+	0x7a, 0x0a, 0xa1, 0x0e, 0x14, 0x8f, 0xc9, 0xa9, 0xbe, 0x4a, 0x7c, 0x8a, 0x30, 0xbf, 0xb7, 0xfd,
+	0x98, 0xe8, 0x1e, 0x50, 0xc4, 0x56, 0xe1, 0x91, 0x61, 0x7d, 0x0d, 0x65, 0xe9, 0xec, 0x92, 0xfa,
+	0x01, 0x54, 0xa4, 0xf3, 0x91, 0x59, 0x58, 0x6c, 0xd0, 0x9d, 0xc0, 0xa1, 0x2e, 0x71, 0xe5, 0x39,
+	0xac, 0xa5, 0xac, 0x1f, 0xa1, 0x99, 0xc7, 0x97, 0xee, 0x43, 0x50, 0xe2, 0xa7, 0x61, 0x62, 0x81,
+	0xfc, 0x16, 0x66, 0xa9, 0x04, 0xa8, 0x6a, 0x54, 0x84, 0x40, 0x7d, 0x7b, 0x4a, 0x7c, 0x59, 0x88,
+	0x75, 0xac, 0x08, 0xeb, 0x97, 0x22, 0xc0, 0xae, 0xc7, 0xcf, 0x5d, 0x59, 0x77, 0xa1, 0xaa, 0xab,
+	0x5f, 0xa7, 0xf7, 0x62, 0xe6, 0x80, 0xae, 0x14, 0x9c, 0x48, 0xa0, 0x01, 0xd4, 0x42, 0xe6, 0x51,
+	0xe6, 0xf1, 0x53, 0x69, 0x4b, 0x7b, 0x88, 0x72, 0xf3, 0x48, 0x73, 0x70, 0x2a, 0x83, 0x6e, 0x02,
+	0xbc, 0xb7, 0x7d, 0xcf, 0xb5, 0xb9, 0x47, 0x03, 0x6d, 0x67, 0x0e, 0x11, 0x3d, 0x73, 0x44, 0x7d,
+	0x97, 0xc6, 0x7c, 0xc2, 0x04, 0x22, 0x7b, 0xc6, 0xc0, 0x4d, 0x0d, 0x62, 0x81, 0x09, 0x3f, 0x0f,
+	0xa8, 0xef, 0x46, 0x66, 0xa5, 0x67, 0xf4, 0xcb, 0x58, 0x11, 0x22, 0x4e, 0x11, 0x21, 0xae, 0x59,
+	0xed, 0x19, 0xfd, 0x22, 0x96, 0xdf, 0xa2, 0x71, 0x39, 0xb3, 0xbd, 0x40, 0x74, 0x5f, 0x4d, 0x2a,
+	0x4b, 0x69, 0x74, 0x1d, 0xea, 0x8e, 0x1d, 0xb8, 0x42, 0x33, 0x31, 0xeb, 0xb2, 0x35, 0x33, 0x40,
+	0x4c, 0xa8, 0x19, 0xe1, 0xcc, 0x73, 0x4c, 0x90, 0xe7, 0x34, 0x85, 0xb6, 0xa0, 0xce, 0x8f, 0x18,
+	0x89, 0x84, 0x41, 0x66, 0x43, 0xc6, 0xe7, 0xfa, 0xd2, 0x7c, 0x7a, 0x4e, 0xe3, 0xa9, 0x4f, 0xde,
+	0x88, 0x5a, 0xc1, 0x99, 0xb8, 0xf5, 0x7b, 0x01, 0x1a, 0x32, 0x13, 0xe7, 0xec, 0x4d, 0x11, 0x8d,
+	0x90, 0x11, 0xd7, 0x73, 0x44, 0x6c, 0xb2, 0x49, 0xdc, 0xcc, 0xc0, 0x91, 0x8b, 0x2e, 0x43, 0x85,
+	0xc5, 0x92, 0x5b, 0x54, 0x69, 0x67, 0x71, 0xa0, 0xce, 0x26, 0xae, 0x4e, 0x64, 0x23, 0x95, 0x64,
+	0xb0, 0x9a, 0x09, 0x88, 0xe9, 0x49, 0x84, 0x9e, 0xc0, 0xc5, 0x2c, 0xf8, 0x93, 0xc8, 0xa1, 0x8c,
+	0x44, 0x66, 0xb9, 0x57, 0x9c, 0x6f, 0xea, 0x57, 0x02, 0xc7, 0x9d, 0x4c, 0x52, 0x02, 0x91, 0xd0,
+	0xfc, 0x96, 0x4e, 0x85, 0xe6, 0x8a, 0xd2, 0xfc, 0x96, 0x4e, 0x47, 0x6e, 0x6e, 0xb8, 0x57, 0xe7,
+	0x86, 0xfb, 0xc3, 0x7c, 0xc0, 0x6b, 0x32, 0x74, 0xd7, 0x32, 0x25, 0xcf, 0x12, 0x16, 0x26, 0x51,
+	0xec, 0xf3, 0x5c, 0x2e, 0xac, 0x8f, 0x45, 0x58, 0x5b, 0x60, 0xe7, 0xf2, 0x63, 0xcc, 0xe5, 0xe7,
+	0x7a, 0x3e, 0x3f, 0x05, 0x59, 0x3c, 0x19, 0x90, 0x2f, 0x2f, 0x87, 0xc6, 0x01, 0x97, 0x21, 0x2b,
+	0xa7, 0xe5, 0xf5, 0x4c, 0x60, 0xe8, 0x29, 0xb4, 0x9c, 0x98, 0x31, 0x12, 0x70, 0x15, 0x11, 0xb3,
+	0x74, 0x8e, 0x34, 0x37, 0xf5, 0x11, 0x19, 0x1a, 0xb4, 0x01, 0x6b, 0xa9, 0xf9, 0xfa, 0x12, 0x55,
+	0xc8, 0xed, 0x14, 0x56, 0x82, 0x5f, 0x40, 0xc3, 0x9b, 0x85, 0x8c, 0xbe, 0x27, 0x33, 0x12, 0x70,
+	0xb3, 0x72, 0x0e, 0x4d, 0xf9, 0x03, 0xf2, 0x65, 0x62, 0x74, 0x46, 0xb3, 0x68, 0xa7, 0x34, 0x7a,
+	0x00, 0xed, 0x39, 0x3f, 0x22, 0xb3, 0xb6, 0x3a, 0xb3, 0xad, 0xbc, 0xed, 0x11, 0xda, 0x82, 0xce,
+	0x82, 0xf1, 0x91, 0x59, 0x5f, 0x7d, 0x72, 0x6d, 0xde, 0x9d, 0xc8, 0xfa, 0x1c, 0xca, 0xca, 0xb1,
+	0x4f, 0xe5, 0x67, 0x6e, 0xa0, 0x1a, 0x7a, 0xa0, 0x5a, 0x1f, 0x0d, 0x68, 0xef, 0x33, 0xea, 0xc6,
+	0x0e, 0xf9, 0x77, 0xcc, 0x29, 0x13, 0xaa, 0x34, 0xe6, 0x61, 0xcc, 0x45, 0xdf, 0xc8, 0x77, 0x49,
+	0x93, 0xd6, 0xaf, 0x05, 0x58, 0x4b, 0x4d, 0xfd, 0x47, 0x1b, 0xf9, 0x01, 0x34, 0x32, 0x3a, 0x79,
+	0xf8, 0xd6, 0xf3, 0x66, 0x26, 0x4c, 0x9c, 0x17, 0x44, 0x77, 0xe6, 0x6d, 0x6d, 0x0c, 0x3b, 0xd9,
+	0x99, 0x97, 0x92, 0x91, 0x5a, 0x8f, 0xae, 0x42, 0x55, 0x0d, 0x0b, 0xd5, 0xe6, 0x75, 0x5c, 0x91,
+	0xd3, 0x22, 0x42, 0xf7, 0xa1, 0x76, 0x60, 0x7b, 0x7e, 0x2c, 0x92, 0x5d, 0x59, 0xd4, 0x8c, 0xe9,
+	0xc9, 0xae, 0x62, 0xe2, 0x54, 0x2a, 0xd7, 0xfd, 0xd5, 0x5c, 0xf7, 0x5b, 0x3f, 0x17, 0x00, 0x32,
+	0x4b, 0x97, 0x5e, 0xb3, 0x95, 0x0f, 0x2a, 0x7a, 0x02, 0xe0, 0xd0, 0xe0, 0xc0, 0x73, 0x49, 0xe0,
+	0x24, 0xdb, 0xe4, 0xdf, 0x77, 0x41, 0x4e, 0x1e, 0xbd, 0x10, 0xd1, 0xa5, 0x53, 0x7b, 0xea, 0xf9,
+	0x1e, 0xf7, 0x48, 0x12, 0x86, 0x8d, 0x55, 0xa1, 0x1b, 0xec, 0xe7, 0x25, 0xd5, 0x26, 0x30, 0x7f,
+	0xba, 0xfb, 0x15, 0xa0, 0x65, 0xa1, 0xb3, 0x76, 0x03, 0x23, 0xbf, 0x1b, 0xbc, 0x83, 0x8a, 0x0a,
+	0xfc, 0x8a, 0x53, 0x08, 0x4a, 0x81, 0x3d, 0x4b, 0x9f, 0x73, 0xf1, 0x9d, 0xdf, 0x82, 0x8a, 0xf3,
+	0x5b, 0xd0, 0x86, 0xde, 0x82, 0x94, 0x47, 0x97, 0x96, 0x12, 0x9b, 0xee, 0x42, 0xb7, 0xa1, 0x9e,
+	0x42, 0xa2, 0xf9, 0xa4, 0x31, 0xea, 0x3f, 0x42, 0x1d, 0x6b, 0xca, 0x3a, 0x04, 0xc8, 0x52, 0xb9,
+	0x2a, 0x35, 0x84, 0x31, 0xca, 0x92, 0xd4, 0x48, 0x42, 0x0e, 0x54, 0x66, 0x3b, 0x64, 0x6a, 0x3b,
+	0xc7, 0xfa, 0x85, 0xc9, 0x80, 0xdc, 0xe3, 0x53, 0xca, 0x3d, 0x3e, 0x77, 0x5e, 0x40, 0x2d, 0x69,
+	0x2a, 0xb4, 0x0e, 0x9d, 0x7d, 0x3c, 0x7a, 0x89, 0x47, 0xe3, 0xef, 0x27, 0xcf, 0x77, 0x76, 0x9f,
+	0xbe, 0xde, 0x1b, 0x77, 0x2e, 0xa0, 0x8b, 0xd0, 0x4a, 0xd1, 0xed, 0xd7, 0x7b, 0xdf, 0x76, 0x0c,
+	0x64, 0xc2, 0x7a, 0x0a, 0x8d, 0xbe, 0x1b, 0xef, 0xe0, 0xa7, 0xcf, 0xc6, 0xa3, 0x37, 0x3b, 0x9d,
+	0xc2, 0xf0, 0x8f, 0x02, 0xd4, 0x76, 0xb4, 0xe7, 0x68, 0x1f, 0x5a, 0x73, 0x7f, 0x8e, 0xd0, 0xcd,
+	0x2c, 0x2a, 0xab, 0xfe, 0x4d, 0x75, 0x6f, 0x7d, 0x92, 0xaf, 0xdb, 0xf7, 0x31, 0x54, 0xd4, 0xd6,
+	0x8c, 0x72, 0xcb, 0xe2, 0xdc, 0x3f, 0x89, 0xae, 0xb9, 0xcc, 0xd0, 0x87, 0x47, 0xd0, 0xcc, 0x2f,
+	0xf0, 0xe8, 0xc6, 0xa2, 0xe4, 0xdc, 0x62, 0xff, 0xe9, 0x8b, 0xfa, 0x06, 0x1a, 0x42, 0x71, 0xd7,
+	0xe3, 0x28, 0xd7, 0x78, 0xd9, 0xde, 0xd6, 0xbd, 0xbc, 0x80, 0x6a, 0xf5, 0xdb, 0x50, 0xd5, 0xd3,
+	0x08, 0x99, 0xf9, 0x7a, 0xcf, 0xcf, 0xd2, 0xee, 0xb5, 0x15, 0x9c, 0x44, 0xeb, 0x7d, 0x63, 0x7b,
+	0xeb, 0x87, 0x47, 0x87, 0x1e, 0x3f, 0x8a, 0xa7, 0x03, 0x87, 0xce, 0x36, 0xe3, 0xc0, 0x39, 0xb2,
+	0x19, 0x27, 0xee, 0x3d, 0xd7, 0x8b, 0xb8, 0xe7, 0x6f, 0xaa, 0x9f, 0x7b, 0xc9, 0x38, 0xbb, 0x97,
+	0xdc, 0xb5, 0xc9, 0x42, 0x67, 0x5a, 0x91, 0xdd, 0xf9, 0xff, 0xbf, 0x06, 0x00, 0x2b, 0x9f, 0xb0,
+	0xee, 0x26, 0x0f, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ExecuterClient is the client API for Executer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ExecuterClient interface {
+	// ListPipelines lists the pipelines the caller can access.
+	ListPipelines(ctx context.Context, in *ListPipelinesRequest, opts ...grpc.CallOption) (*ListPipelinesResponse, error)
+	// Upload stores a pipeline along with its dataset schema and problem.
+	Upload(ctx context.Context, in *UploadRequest, opts ...grpc.CallOption) (*UploadResponse, error)
+	// UploadFitted stores a fitted pipeline sent in chunks.
+	UploadFitted(ctx context.Context, opts ...grpc.CallOption) (Executer_UploadFittedClient, error)
+	// Fit fits the pipeline using the labelled dataset.
+	Fit(ctx context.Context, in *FitRequest, opts ...grpc.CallOption) (*FitResponse, error)
+	// Produce produces predictions for every dataset sent, streaming back the
+	// predictions of each dataset in the order they were received.
+	Produce(ctx context.Context, opts ...grpc.CallOption) (Executer_ProduceClient, error)
+}
+
+type executerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewExecuterClient(cc *grpc.ClientConn) ExecuterClient {
+	return &executerClient{cc}
+}
+
+func (c *executerClient) ListPipelines(ctx context.Context, in *ListPipelinesRequest, opts ...grpc.CallOption) (*ListPipelinesResponse, error) {
+	out := new(ListPipelinesResponse)
+	err := c.cc.Invoke(ctx, "/executer.Executer/ListPipelines", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *executerClient) Upload(ctx context.Context, in *UploadRequest, opts ...grpc.CallOption) (*UploadResponse, error) {
+	out := new(UploadResponse)
+	err := c.cc.Invoke(ctx, "/executer.Executer/Upload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *executerClient) UploadFitted(ctx context.Context, opts ...grpc.CallOption) (Executer_UploadFittedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Executer_serviceDesc.Streams[0], "/executer.Executer/UploadFitted", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &executerUploadFittedClient{stream}
+	return x, nil
+}
+
+type Executer_UploadFittedClient interface {
+	Send(*UploadFittedRequest) error
+	CloseAndRecv() (*UploadResponse, error)
+	grpc.ClientStream
+}
+
+type executerUploadFittedClient struct {
+	grpc.ClientStream
+}
+
+func (x *executerUploadFittedClient) Send(m *UploadFittedRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *executerUploadFittedClient) CloseAndRecv() (*UploadResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *executerClient) Fit(ctx context.Context, in *FitRequest, opts ...grpc.CallOption) (*FitResponse, error) {
+	out := new(FitResponse)
+	err := c.cc.Invoke(ctx, "/executer.Executer/Fit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *executerClient) Produce(ctx context.Context, opts ...grpc.CallOption) (Executer_ProduceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Executer_serviceDesc.Streams[1], "/executer.Executer/Produce", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &executerProduceClient{stream}
+	return x, nil
+}
+
+type Executer_ProduceClient interface {
+	Send(*ProduceRequest) error
+	Recv() (*ProduceResponse, error)
+	grpc.ClientStream
+}
+
+type executerProduceClient struct {
+	grpc.ClientStream
+}
+
+func (x *executerProduceClient) Send(m *ProduceRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *executerProduceClient) Recv() (*ProduceResponse, error) {
+	m := new(ProduceResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ExecuterServer is the server API for Executer service.
+type ExecuterServer interface {
+	// ListPipelines lists the pipelines the caller can access.
+	ListPipelines(context.Context, *ListPipelinesRequest) (*ListPipelinesResponse, error)
+	// Upload stores a pipeline along with its dataset schema and problem.
+	Upload(context.Context, *UploadRequest) (*UploadResponse, error)
+	// UploadFitted stores a fitted pipeline sent in chunks.
+	UploadFitted(Executer_UploadFittedServer) error
+	// Fit fits the pipeline using the labelled dataset.
+	Fit(context.Context, *FitRequest) (*FitResponse, error)
+	// Produce produces predictions for every dataset sent, streaming back the
+	// predictions of each dataset in the order they were received.
+	Produce(Executer_ProduceServer) error
+}
+
+// UnimplementedExecuterServer can be embedded to have forward compatible implementations.
+type UnimplementedExecuterServer struct {
+}
+
+func (*UnimplementedExecuterServer) ListPipelines(ctx context.Context, req *ListPipelinesRequest) (*ListPipelinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPipelines not implemented")
+}
+func (*UnimplementedExecuterServer) Upload(ctx context.Context, req *UploadRequest) (*UploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Upload not implemented")
+}
+func (*UnimplementedExecuterServer) UploadFitted(srv Executer_UploadFittedServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadFitted not implemented")
+}
+func (*UnimplementedExecuterServer) Fit(ctx context.Context, req *FitRequest) (*FitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fit not implemented")
+}
+func (*UnimplementedExecuterServer) Produce(srv Executer_ProduceServer) error {
+	return status.Errorf(codes.Unimplemented, "method Produce not implemented")
+}
+
+func RegisterExecuterServer(s *grpc.Server, srv ExecuterServer) {
+	s.RegisterService(&_Executer_serviceDesc, srv)
+}
+
+func _Executer_ListPipelines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPipelinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecuterServer).ListPipelines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/executer.Executer/ListPipelines",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecuterServer).ListPipelines(ctx, req.(*ListPipelinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Executer_Upload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecuterServer).Upload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/executer.Executer/Upload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecuterServer).Upload(ctx, req.(*UploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Executer_UploadFitted_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ExecuterServer).UploadFitted(&executerUploadFittedServer{stream})
+}
+
+type Executer_UploadFittedServer interface {
+	SendAndClose(*UploadResponse) error
+	Recv() (*UploadFittedRequest, error)
+	grpc.ServerStream
+}
+
+type executerUploadFittedServer struct {
+	grpc.ServerStream
+}
+
+func (x *executerUploadFittedServer) SendAndClose(m *UploadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *executerUploadFittedServer) Recv() (*UploadFittedRequest, error) {
+	m := new(UploadFittedRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Executer_Fit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecuterServer).Fit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/executer.Executer/Fit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecuterServer).Fit(ctx, req.(*FitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Executer_Produce_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ExecuterServer).Produce(&executerProduceServer{stream})
+}
+
+type Executer_ProduceServer interface {
+	Send(*ProduceResponse) error
+	Recv() (*ProduceRequest, error)
+	grpc.ServerStream
+}
+
+type executerProduceServer struct {
+	grpc.ServerStream
+}
+
+func (x *executerProduceServer) Send(m *ProduceResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *executerProduceServer) Recv() (*ProduceRequest, error) {
+	m := new(ProduceRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Executer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "executer.Executer",
+	HandlerType: (*ExecuterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPipelines",
+			Handler:    _Executer_ListPipelines_Handler,
+		},
+		{
+			MethodName: "Upload",
+			Handler:    _Executer_Upload_Handler,
+		},
+		{
+			MethodName: "Fit",
+			Handler:    _Executer_Fit_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadFitted",
+			Handler:       _Executer_UploadFitted_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Produce",
+			Handler:       _Executer_Produce_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "executer.proto",
+}
@@ -0,0 +1,364 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. executer.proto
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/admission"
+	"github.com/uncharted-distil/distil-pipeline-executer/auth"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// Server implements the Executer service using the same task functions,
+// queue, job store, admission controller and rate limits as the HTTP routes.
+type Server struct {
+	config        *env.Config
+	queue         *task.Queue
+	jobs          *task.JobStore
+	controller    *admission.Controller
+	limits        *admission.Limits
+	authenticator *auth.Authenticator
+}
+
+// NewServer creates the Executer service. A nil authenticator disables
+// authentication, a nil controller disables admission control and nil limits
+// disable rate limiting.
+func NewServer(config *env.Config, queue *task.Queue, jobs *task.JobStore, controller *admission.Controller, limits *admission.Limits, authenticator *auth.Authenticator) *Server {
+	return &Server{
+		config:        config,
+		queue:         queue,
+		jobs:          jobs,
+		controller:    controller,
+		limits:        limits,
+		authenticator: authenticator,
+	}
+}
+
+// Listen serves the service on the port in the background, returning the
+// gRPC server so it can be stopped.
+func Listen(port string, server *Server) (*grpc.Server, error) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to listen on port %s", port)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryErrors(server.config), unaryAuthenticate(server.authenticator)),
+		grpc.ChainStreamInterceptor(streamErrors(server.config), streamAuthenticate(server.authenticator)))
+	RegisterExecuterServer(grpcServer, server)
+
+	go func() {
+		err := grpcServer.Serve(listener)
+		if err != nil {
+			log.Errorf("%+v", errors.Wrap(err, "gRPC server stopped"))
+		}
+	}()
+
+	return grpcServer, nil
+}
+
+// ListPipelines lists the pipelines the caller can access.
+func (s *Server) ListPipelines(ctx context.Context, req *ListPipelinesRequest) (*ListPipelinesResponse, error) {
+	err := authorize(ctx, s.authenticator, auth.ScopeRead, "")
+	if err != nil {
+		return nil, err
+	}
+
+	pipelines, err := task.GetPipelines(s.config.PipelineDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get pipelines from directory '%s'", s.config.PipelineDir)
+	}
+
+	principal := auth.FromContext(ctx)
+	res := &ListPipelinesResponse{}
+	for _, p := range pipelines {
+		if principal == nil || principal.CanAccess(p.PipelineID) {
+			res.Pipelines = append(res.Pipelines, toPipelineInfo(p))
+		}
+	}
+
+	return res, nil
+}
+
+// Upload stores a pipeline along with its dataset schema and problem.
+func (s *Server) Upload(ctx context.Context, req *UploadRequest) (*UploadResponse, error) {
+	err := s.checkPipeline(ctx, auth.ScopeAdmin, req.PipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.DatasetSchema) == 0 {
+		return nil, util.NewInvalidError("dataset schema not provided in upload")
+	}
+	if len(req.Pipeline) == 0 {
+		return nil, util.NewInvalidError("pipeline not provided in upload")
+	}
+	if len(req.Problem) == 0 {
+		return nil, util.NewInvalidError("problem not provided in upload")
+	}
+
+	err = task.StorePipeline(req.PipelineId, req.Pipeline, req.DatasetSchema, req.Problem, !req.KeepExisting)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadResponse{PipelineId: req.PipelineId}, nil
+}
+
+// UploadFitted stores a fitted pipeline sent in chunks.
+func (s *Server) UploadFitted(stream Executer_UploadFittedServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	pipelineID := req.PipelineId
+	err = s.checkPipeline(stream.Context(), auth.ScopeAdmin, pipelineID)
+	if err != nil {
+		return err
+	}
+
+	var fitted bytes.Buffer
+	for {
+		fitted.Write(req.Chunk)
+		req, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	err = task.UpdatePipeline(pipelineID, fitted.Bytes(), true)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&UploadResponse{PipelineId: pipelineID})
+}
+
+// Fit fits the pipeline using the labelled dataset.
+func (s *Server) Fit(ctx context.Context, req *FitRequest) (*FitResponse, error) {
+	err := s.checkPipeline(ctx, auth.ScopeFit, req.PipelineId)
+	if err != nil {
+		return nil, err
+	}
+	err = task.CheckTrainingMode(req.Training)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &FitResponse{PipelineId: req.PipelineId}
+	res.JobId, err = s.runJob(ctx, task.JobFit, req.PipelineId, toPriority(req.Priority, admission.PriorityBulk), func(ctx context.Context) error {
+		ds, err := s.parseDataset(ctx, req.PipelineId, req.Dataset)
+		if err != nil {
+			return err
+		}
+		res.PredictionId = ds.GetPredictionsID()
+
+		options := &task.FitOptions{Training: req.Training}
+		if req.Validation != task.ValidationNone {
+			options.Validation = s.validationOptions(req)
+		}
+		if req.Candidate {
+			options.Candidate = s.candidateOptions(req)
+		}
+
+		result, err := task.FitDataset(ctx, req.PipelineId, ds, options, s.config)
+		if err != nil {
+			return err
+		}
+		res.TrainingRows = int32(result.TrainingRows)
+		if result.Validation != nil {
+			res.ValidationScores = toScores(result.Validation.Scores)
+		}
+		if result.Candidate != nil {
+			res.Candidate = toCandidateResult(result.Candidate)
+		}
+		res.Fitted = result.Fitted
+		res.RunId = result.RunID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Produce produces predictions for every dataset received, one at a time,
+// sending back the predictions of each as soon as they are available.
+func (s *Server) Produce(stream Executer_ProduceServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		res, err := s.produce(stream.Context(), req)
+		if err != nil {
+			return err
+		}
+		err = stream.Send(res)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) produce(ctx context.Context, req *ProduceRequest) (*ProduceResponse, error) {
+	err := s.checkPipeline(ctx, auth.ScopeProduce, req.PipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ProduceResponse{PipelineId: req.PipelineId}
+	priority := toPriority(req.Priority, admission.PriorityInteractive)
+	res.JobId, err = s.runJob(ctx, task.JobProduce, req.PipelineId, priority, func(ctx context.Context) error {
+		ds, err := s.parseDataset(ctx, req.PipelineId, req.Dataset)
+		if err != nil {
+			return err
+		}
+		res.PredictionId = ds.GetPredictionsID()
+
+		// queue the rows, served according to the request priority
+		predictions, parsed, err := task.ProduceDataset(ctx, req.PipelineId, ds, s.queue, int(priority), s.config)
+		if err != nil {
+			return err
+		}
+		res.Predictions = toPredictions(parsed)
+		res.Outputs = toOutputs(predictions, req.Outputs)
+		res.RunIds = predictions.RunIDs
+		res.Failures = toFailures(predictions.Failures)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// validationOptions returns the validation settings of the request, falling
+// back to the default holdout ratio and fold count.
+func (s *Server) validationOptions(req *FitRequest) *task.ValidationOptions {
+	options := &task.ValidationOptions{
+		Method:       req.Validation,
+		HoldoutRatio: task.DefaultHoldoutRatio,
+		Folds:        task.DefaultFolds,
+		Seed:         req.Seed,
+	}
+	if req.HoldoutRatio > 0 {
+		options.HoldoutRatio = req.HoldoutRatio
+	}
+	if req.Folds > 0 {
+		options.Folds = int(req.Folds)
+	}
+	return options
+}
+
+// candidateOptions returns the promotion settings of the request, falling
+// back to the configured ones.
+func (s *Server) candidateOptions(req *FitRequest) *task.CandidateOptions {
+	options := &task.CandidateOptions{
+		Metric:       s.config.PromotionMetric,
+		Threshold:    s.config.PromotionThreshold,
+		HoldoutRatio: s.config.PromotionHoldoutRatio,
+		Seed:         req.Seed,
+	}
+	if req.Metric != "" {
+		options.Metric = req.Metric
+	}
+	if req.Threshold != nil {
+		options.Threshold = req.Threshold.Value
+	}
+	return options
+}
+
+// checkPipeline validates the pipeline id and makes sure the caller can act
+// on the pipeline.
+func (s *Server) checkPipeline(ctx context.Context, scope auth.Scope, pipelineID string) error {
+	err := env.ValidateID(pipelineID)
+	if err != nil {
+		return util.WithKind(errors.Wrap(err, "invalid pipeline-id"), util.KindBadRequest)
+	}
+	return authorize(ctx, s.authenticator, scope, pipelineID)
+}
+
+func (s *Server) parseDataset(ctx context.Context, pipelineID string, ds *Dataset) (task.DatasetConstructor, error) {
+	data, err := datasetJSON(ds)
+	if err != nil {
+		return nil, err
+	}
+	return task.ParseDataset(ctx, pipelineID, data, task.FormatJSON)
+}
+
+// runJob persists the progress and outcome of the call as a job, once the
+// rate limits and admission controller let it through. Rejected calls leave
+// no job behind.
+func (s *Server) runJob(ctx context.Context, jobType string, pipelineID string, priority admission.Priority, run func(ctx context.Context) error) (string, error) {
+	release, err := s.admit(ctx, pipelineID, priority)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	job, err := s.jobs.Create(env.NewID(), jobType, pipelineID)
+	if err != nil {
+		return "", err
+	}
+	err = job.Start()
+	if err == nil {
+		err = run(admission.NewContext(task.NewJobContext(ctx, job), priority))
+	}
+	finishErr := job.Finish(err)
+	if finishErr != nil {
+		log.Warnf("unable to finish job '%s': %+v", job.JobID, finishErr)
+	}
+
+	return job.JobID, err
+}
+
+// admit applies the rate limits and waits for the admission controller to
+// let the call through, returning the function releasing its slot.
+func (s *Server) admit(ctx context.Context, pipelineID string, priority admission.Priority) (func(), error) {
+	address := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		address = p.Addr.String()
+	}
+	_, err := s.limits.Allow(admission.ClientKey(ctx, address), pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.controller.Acquire(ctx, priority)
+}
@@ -2,12 +2,21 @@ from d3m import runtime, cli
 from d3m.container import dataset
 import logging
 import argparse
+import contextlib
 import pathlib
 import pickle
 import os
 import typing
 import sys
 
+# tracing is optional and only enabled when the opentelemetry packages are
+# installed and the calling service passed a trace context
+try:
+    from opentelemetry import trace
+    from opentelemetry.trace.propagation.tracecontext import TraceContextTextMapPropagator
+except ImportError:
+    trace = None
+
 def main(argv: typing.Sequence) -> None:
 
     # Fit and pickle a pipeline
@@ -36,13 +45,56 @@ def main(argv: typing.Sequence) -> None:
     cli.configure_parser(parser)
     arguments = parser.parse_args(argv[1:])
 
-    if arguments.runtime_command == 'produce':
-        fitted_pipeline = pickle.load(arguments.fitted_pipeline)
-        dataset_uri = pathlib.Path(os.path.abspath(arguments.test_inputs[0])).as_uri()
-        results = produce(fitted_pipeline, dataset_uri)
-        output_predictions(pathlib.Path(arguments.output.name).parent.resolve(), results)
-    else:
-        cli.handler(arguments, parser)
+    provider = configure_tracing()
+    try:
+        with traced('runner.{}'.format(arguments.runtime_command), linked=True):
+            if arguments.runtime_command == 'produce':
+                fitted_pipeline = pickle.load(arguments.fitted_pipeline)
+                dataset_uri = pathlib.Path(os.path.abspath(arguments.test_inputs[0])).as_uri()
+                results = produce(fitted_pipeline, dataset_uri)
+                with traced('runner.output_predictions'):
+                    output_predictions(pathlib.Path(arguments.output.name).parent.resolve(), results)
+                if getattr(arguments, 'output_run', None) is not None:
+                    results.pipeline_run.to_yaml(arguments.output_run)
+            else:
+                cli.handler(arguments, parser)
+    finally:
+        if provider is not None:
+            provider.shutdown()
+
+def configure_tracing():
+    # spans are exported to the collector used by the calling service
+    if trace is None or 'TRACEPARENT' not in os.environ or 'OTEL_EXPORTER_OTLP_ENDPOINT' not in os.environ:
+        return None
+    try:
+        from opentelemetry.sdk.resources import Resource
+        from opentelemetry.sdk.trace import TracerProvider
+        from opentelemetry.sdk.trace.export import BatchSpanProcessor
+        from opentelemetry.exporter.otlp.proto.http.trace_exporter import OTLPSpanExporter
+    except ImportError:
+        logging.warning('opentelemetry sdk or otlp exporter not installed so runner spans will not be exported')
+        return None
+
+    provider = TracerProvider(resource=Resource.create({'service.name': 'distil-pipeline-runner'}))
+    provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter()))
+    trace.set_tracer_provider(provider)
+    return provider
+
+@contextlib.contextmanager
+def traced(name: str, linked: bool = False):
+    if trace is None:
+        yield
+        return
+
+    # the root runner span continues the trace of the calling service
+    context = None
+    if linked and 'TRACEPARENT' in os.environ:
+        carrier = {'traceparent': os.environ['TRACEPARENT']}
+        if 'TRACESTATE' in os.environ:
+            carrier['tracestate'] = os.environ['TRACESTATE']
+        context = TraceContextTextMapPropagator().extract(carrier)
+    with trace.get_tracer(__name__).start_as_current_span(name, context=context):
+        yield
 
 def output_predictions(pred_path: str, results: runtime.Result):
     for output_key in results.values:
@@ -51,10 +103,12 @@ def output_predictions(pred_path: str, results: runtime.Result):
             results.values[output_key].to_csv(path, index=False)
 
 def produce(fitted_pipeline: runtime.Runtime, dataset_uri: str) -> runtime.Result:
-    test_dataset = dataset.Dataset.load(dataset_uri)
-    _, result = runtime.produce(
-        fitted_pipeline, [test_dataset], expose_produced_outputs=True
-    )
+    with traced('runner.load_dataset'):
+        test_dataset = dataset.Dataset.load(dataset_uri)
+    with traced('runner.produce'):
+        _, result = runtime.produce(
+            fitted_pipeline, [test_dataset], expose_produced_outputs=True
+        )
     if result.has_error():
         raise result.error
     return result
@@ -0,0 +1,41 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// redirectStdout points the standard output of the process at stderr and
+// returns a writer to the original standard output.
+func redirectStdout() (io.Writer, error) {
+	fd, err := unix.Dup(int(os.Stdout.Fd()))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to duplicate stdout")
+	}
+	err = unix.Dup2(int(os.Stderr.Fd()), int(os.Stdout.Fd()))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to redirect stdout to stderr")
+	}
+	return os.NewFile(uintptr(fd), "stdout"), nil
+}
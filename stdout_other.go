@@ -0,0 +1,31 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+//go:build windows || plan9
+// +build windows plan9
+
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// redirectStdout leaves the standard output of the process as is since its
+// file descriptor cannot be replaced, so logs are written along with the
+// results unless they are written to a file.
+func redirectStdout() (io.Writer, error) {
+	return os.Stdout, nil
+}
@@ -0,0 +1,311 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// BatchSizeThroughput grows or shrinks batches depending on which
+	// direction last improved throughput.
+	BatchSizeThroughput = "throughput"
+	// BatchSizeAIMD grows batches additively and shrinks them
+	// multiplicatively when they fail or take longer than the target.
+	BatchSizeAIMD = "aimd"
+	// BatchSizeFixed always uses the configured batch size.
+	BatchSizeFixed = "fixed"
+
+	batchSizeFile = "batch_size.json"
+)
+
+// BatchSizer decides the size of produce batches from how previous batches
+// went.
+type BatchSizer interface {
+	// Size returns the size of the next batch.
+	Size() int
+	// Observe records the outcome of producing a batch.
+	Observe(rows [][]string, duration time.Duration, failed bool)
+}
+
+// learnedBatchSize is the batch size a pipeline ended its last produce with.
+type learnedBatchSize struct {
+	Size     int       `json:"size"`
+	Strategy string    `json:"strategy"`
+	Updated  time.Time `json:"updated"`
+}
+
+// NewBatchSizer creates the batch sizer configured, starting from the size
+// learned from previous produce calls of the pipeline if any.
+func NewBatchSizer(pipelineID string, config *env.Config) (BatchSizer, error) {
+	start := config.BatchSize
+	learned, err := loadBatchSize(pipelineID)
+	if err != nil {
+		log.Warnf("ignoring learned batch size of pipeline '%s': %v", pipelineID, err)
+	} else if learned != nil && learned.Strategy == config.BatchSizeStrategy {
+		log.Infof("starting from learned batch size %d for pipeline '%s'", learned.Size, pipelineID)
+		start = learned.Size
+	}
+
+	bounds := &batchBounds{
+		min: float64(config.BatchSizeMin),
+		max: float64(config.BatchSizeMax),
+	}
+	var sizer BatchSizer
+	switch config.BatchSizeStrategy {
+	case BatchSizeThroughput:
+		sizer = &throughputSizer{
+			size:      bounds.clamp(float64(start)),
+			increase:  config.BatchSizeIncreaseFactor,
+			decrease:  config.BatchSizeDecreaseFactor,
+			direction: 1,
+			bounds:    bounds,
+		}
+	case BatchSizeAIMD:
+		sizer = &aimdSizer{
+			size:     bounds.clamp(float64(start)),
+			step:     float64(config.BatchAIMDIncrease),
+			decrease: config.BatchAIMDDecreaseFactor,
+			target:   time.Duration(config.BatchAIMDTarget) * time.Second,
+			bounds:   bounds,
+		}
+	case BatchSizeFixed:
+		sizer = &fixedSizer{
+			size: int(bounds.clamp(float64(config.BatchSize))),
+		}
+	default:
+		return nil, errors.Errorf("unknown batch size strategy '%s'", config.BatchSizeStrategy)
+	}
+
+	return &memorySizer{
+		BatchSizer: sizer,
+		limit:      batchMemoryLimit(config),
+		minSize:    config.BatchSizeMin,
+	}, nil
+}
+
+// SaveBatchSize stores the size the sizer reached so the next produce call
+// of the pipeline starts from it.
+func SaveBatchSize(pipelineID string, sizer BatchSizer, config *env.Config) error {
+	data, err := json.Marshal(&learnedBatchSize{
+		Size:     sizer.Size(),
+		Strategy: config.BatchSizeStrategy,
+		Updated:  time.Now(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal batch size")
+	}
+
+	filename := path.Join(env.ResolvePipelinePath(pipelineID), batchSizeFile)
+	err = util.WriteFileWithDirs(filename+".tmp", data, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "unable to write batch size")
+	}
+	return errors.Wrap(os.Rename(filename+".tmp", filename), "unable to replace batch size")
+}
+
+func loadBatchSize(pipelineID string) (*learnedBatchSize, error) {
+	data, err := ioutil.ReadFile(path.Join(env.ResolvePipelinePath(pipelineID), batchSizeFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "unable to read batch size")
+	}
+
+	learned := &learnedBatchSize{}
+	err = json.Unmarshal(data, learned)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse batch size")
+	}
+
+	return learned, nil
+}
+
+type batchBounds struct {
+	min float64
+	max float64
+}
+
+func (b *batchBounds) clamp(size float64) float64 {
+	return math.Max(math.Max(b.min, 1), math.Min(b.max, size))
+}
+
+// throughputSizer hill climbs towards the batch size with the highest
+// throughput, reversing direction whenever throughput drops.
+type throughputSizer struct {
+	size       float64
+	increase   float64
+	decrease   float64
+	direction  int
+	throughput float64
+	bounds     *batchBounds
+}
+
+func (s *throughputSizer) Size() int {
+	return int(s.size)
+}
+
+func (s *throughputSizer) Observe(rows [][]string, duration time.Duration, failed bool) {
+	if failed {
+		s.size = s.bounds.clamp(s.size * s.decrease)
+		s.direction = -1
+		s.throughput = 0
+		log.Infof("batch failed so decreasing batch size to %d", s.Size())
+		return
+	}
+
+	throughput := float64(len(rows)) / math.Max(duration.Seconds(), 0.001)
+	if s.throughput > 0 && throughput < s.throughput {
+		s.direction = -s.direction
+	}
+	log.Infof("latest batch throughput was %v (previous %v)", throughput, s.throughput)
+	s.throughput = throughput
+
+	if s.direction > 0 {
+		s.size = s.bounds.clamp(s.size * s.increase)
+	} else {
+		s.size = s.bounds.clamp(s.size * s.decrease)
+	}
+	log.Infof("batch size set to %d", s.Size())
+}
+
+// aimdSizer grows batches by a fixed step and cuts them by a factor when they
+// fail or exceed the target duration.
+type aimdSizer struct {
+	size     float64
+	step     float64
+	decrease float64
+	target   time.Duration
+	bounds   *batchBounds
+}
+
+func (s *aimdSizer) Size() int {
+	return int(s.size)
+}
+
+func (s *aimdSizer) Observe(rows [][]string, duration time.Duration, failed bool) {
+	if failed || (s.target > 0 && duration > s.target) {
+		s.size = s.bounds.clamp(s.size * s.decrease)
+	} else {
+		s.size = s.bounds.clamp(s.size + s.step)
+	}
+	log.Infof("batch of %d rows took %v (failed: %v) so batch size set to %d", len(rows), duration, failed, s.Size())
+}
+
+type fixedSizer struct {
+	size int
+}
+
+func (s *fixedSizer) Size() int {
+	return s.size
+}
+
+func (s *fixedSizer) Observe(rows [][]string, duration time.Duration, failed bool) {}
+
+// memorySizer caps batches so their data stays under a memory limit, based
+// on the average size of the rows seen so far.
+type memorySizer struct {
+	BatchSizer
+	limit    int64
+	minSize  int
+	rowBytes float64
+	rowCount float64
+}
+
+func (s *memorySizer) Size() int {
+	size := s.BatchSizer.Size()
+	if s.limit <= 0 || s.rowCount == 0 {
+		return size
+	}
+
+	capped := int(float64(s.limit) / (s.rowBytes / s.rowCount))
+	if capped < s.minSize {
+		capped = s.minSize
+	}
+	if capped < 1 {
+		capped = 1
+	}
+	if capped < size {
+		return capped
+	}
+	return size
+}
+
+func (s *memorySizer) Observe(rows [][]string, duration time.Duration, failed bool) {
+	for _, r := range rows {
+		for _, v := range r {
+			s.rowBytes = s.rowBytes + float64(len(v)+1)
+		}
+	}
+	s.rowCount = s.rowCount + float64(len(rows))
+	s.BatchSizer.Observe(rows, duration, failed)
+}
+
+// batchMemoryLimit returns the bytes of row data a batch may hold, using the
+// configured limit or a fraction of the available memory.
+func batchMemoryLimit(config *env.Config) int64 {
+	if config.BatchMemoryLimitMB > 0 {
+		return int64(config.BatchMemoryLimitMB) * 1024 * 1024
+	}
+	if config.BatchMemoryFraction <= 0 {
+		return 0
+	}
+
+	available, err := availableMemory()
+	if err != nil {
+		log.Warnf("unable to determine available memory so batches are not capped: %v", err)
+		return 0
+	}
+	return int64(float64(available) * config.BatchMemoryFraction)
+}
+
+// availableMemory reads the memory available for new processes on linux.
+func availableMemory() (int64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to open meminfo")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0, errors.Wrap(err, "unable to parse available memory")
+			}
+			return kb * 1024, nil
+		}
+	}
+
+	return 0, errors.New("available memory not found in meminfo")
+}
@@ -0,0 +1,371 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
+)
+
+var (
+	predictionCache *PredictionCache
+)
+
+// SetPredictionCache sets the cache used when producing predictions. A nil
+// cache disables caching.
+func SetPredictionCache(cache *PredictionCache) {
+	predictionCache = cache
+}
+
+// cachedOutput holds the output rows produced for a single input row.
+type cachedOutput struct {
+	name      string
+	header    []string
+	d3mColumn int
+	rows      [][]string
+}
+
+type cacheEntry struct {
+	key        string
+	pipelineID string
+	outputs    map[string]*cachedOutput
+	expires    time.Time
+}
+
+// PredictionCache caches the outputs produced for input rows, keyed by the
+// fitted version of the pipeline and the row content. Least recently used
+// rows are evicted once the cache is full.
+type PredictionCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mutex    sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List
+	versions map[string]string
+}
+
+// NewPredictionCache creates a cache holding up to maxEntries rows for at
+// most ttl. A nil cache is returned if maxEntries is not positive.
+func NewPredictionCache(maxEntries int, ttl time.Duration) *PredictionCache {
+	if maxEntries <= 0 {
+		return nil
+	}
+	return &PredictionCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		versions:   make(map[string]string),
+	}
+}
+
+// pipelineVersion identifies the current fit of the pipeline.
+func pipelineVersion(pipelineID string) (string, error) {
+	info, err := os.Stat(env.ResolvePipelineD3MPath(pipelineID))
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to read fitted pipeline '%s'", pipelineID)
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// checkVersion drops the cached rows of the pipeline if it was refitted and
+// returns the current version. The mutex must be held.
+func (c *PredictionCache) checkVersion(pipelineID string) (string, error) {
+	version, err := pipelineVersion(pipelineID)
+	if err != nil {
+		return "", err
+	}
+	if previous, ok := c.versions[pipelineID]; ok && previous != version {
+		log.Infof("pipeline '%s' was refitted so dropping its cached predictions", pipelineID)
+		for e := c.lru.Front(); e != nil; {
+			next := e.Next()
+			if e.Value.(*cacheEntry).pipelineID == pipelineID {
+				c.remove(e)
+			}
+			e = next
+		}
+	}
+	c.versions[pipelineID] = version
+
+	return version, nil
+}
+
+// lookup splits the rows into those with cached outputs, returned as a
+// produce result, and those that still need to be produced.
+func (c *PredictionCache) lookup(pipelineID string, columns []string, d3mIndex int, media mediaColumns, rows [][]string) (*ProduceResult, [][]string) {
+	if c == nil {
+		return nil, rows
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	version, err := c.checkVersion(pipelineID)
+	if err != nil {
+		log.Warnf("skipping prediction cache: %v", err)
+		return nil, rows
+	}
+
+	now := time.Now()
+	var cached *ProduceResult
+	misses := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		content, err := media.rowContent(row)
+		if err != nil {
+			log.Warnf("not using cached predictions for row '%s': %v", row[d3mIndex], err)
+			misses = append(misses, row)
+			continue
+		}
+		e, ok := c.entries[cacheKey(pipelineID, version, columns, d3mIndex, content)]
+		if ok && now.After(e.Value.(*cacheEntry).expires) {
+			c.remove(e)
+			ok = false
+		}
+		if !ok {
+			misses = append(misses, row)
+			continue
+		}
+		c.lru.MoveToFront(e)
+
+		if cached == nil {
+			cached = &ProduceResult{
+				Outputs: make(map[string]*Output),
+			}
+		}
+		for key, o := range e.Value.(*cacheEntry).outputs {
+			output, ok := cached.Outputs[key]
+			if !ok {
+				output = &Output{
+					Key:    key,
+					Name:   o.name,
+					Header: o.header,
+				}
+				cached.Outputs[key] = output
+			}
+
+			// the cached rows get the index of the row they now answer
+			for _, r := range o.rows {
+				copied := append([]string{}, r...)
+				copied[o.d3mColumn] = row[d3mIndex]
+				output.Data = append(output.Data, copied)
+			}
+		}
+	}
+
+	hits := len(rows) - len(misses)
+	metrics.AddPredictionCacheLookups(pipelineID, hits, len(misses))
+	if hits > 0 {
+		log.Infof("found cached predictions for %d of %d rows", hits, len(rows))
+	}
+
+	return cached, misses
+}
+
+// store caches the outputs produced for the rows. Nothing is cached if an
+// output cannot be matched to the input rows.
+func (c *PredictionCache) store(pipelineID string, columns []string, d3mIndex int, media mediaColumns, rows [][]string, result *ProduceResult) {
+	if c == nil {
+		return
+	}
+
+	// split the outputs by input row
+	outputs := make(map[string]map[string]*cachedOutput)
+	for key, o := range result.Outputs {
+		d3mColumn := -1
+		for i, h := range o.Header {
+			if h == model.D3MIndexName {
+				d3mColumn = i
+			}
+		}
+		if d3mColumn < 0 {
+			log.Warnf("output '%s' has no d3m index so predictions are not cached", key)
+			return
+		}
+
+		for _, r := range o.Data {
+			if len(r) <= d3mColumn {
+				continue
+			}
+			byKey, ok := outputs[r[d3mColumn]]
+			if !ok {
+				byKey = make(map[string]*cachedOutput)
+				outputs[r[d3mColumn]] = byKey
+			}
+			cached, ok := byKey[key]
+			if !ok {
+				cached = &cachedOutput{
+					name:      o.Name,
+					header:    o.Header,
+					d3mColumn: d3mColumn,
+				}
+				byKey[key] = cached
+			}
+			cached.rows = append(cached.rows, r)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	version, err := c.checkVersion(pipelineID)
+	if err != nil {
+		log.Warnf("skipping prediction cache: %v", err)
+		return
+	}
+
+	expires := time.Now().Add(c.ttl)
+	for _, row := range rows {
+		rowOutputs, ok := outputs[row[d3mIndex]]
+		if !ok {
+			continue
+		}
+		content, err := media.rowContent(row)
+		if err != nil {
+			log.Warnf("not caching predictions for row '%s': %v", row[d3mIndex], err)
+			continue
+		}
+		key := cacheKey(pipelineID, version, columns, d3mIndex, content)
+		if e, ok := c.entries[key]; ok {
+			c.remove(e)
+		}
+		c.entries[key] = c.lru.PushFront(&cacheEntry{
+			key:        key,
+			pipelineID: pipelineID,
+			outputs:    rowOutputs,
+			expires:    expires,
+		})
+	}
+
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove drops an entry. The mutex must be held.
+func (c *PredictionCache) remove(e *list.Element) {
+	delete(c.entries, e.Value.(*cacheEntry).key)
+	c.lru.Remove(e)
+}
+
+// mediaColumns maps the columns referencing media files to the folder
+// holding the files.
+type mediaColumns map[int]string
+
+// findMediaColumns finds the columns of the main resource referencing media
+// files, resolving their folder relative to the schema file. The metadata
+// only holds table resources so the media resources are read from the
+// schema itself.
+func findMediaColumns(meta *model.Metadata, schemaFile string) (mediaColumns, error) {
+	media := make(mediaColumns)
+	for _, v := range meta.GetMainDataResource().Variables {
+		if v.IsMediaReference() {
+			resID, _ := v.RefersTo["resID"].(string)
+			media[v.Index] = resID
+		}
+	}
+	if len(media) == 0 {
+		return media, nil
+	}
+
+	data, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read dataset schema")
+	}
+	schema := &struct {
+		DataResources []*model.DataResource `json:"dataResources"`
+	}{}
+	err = json.Unmarshal(data, schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse dataset schema")
+	}
+	folders := make(map[string]string)
+	for _, dr := range schema.DataResources {
+		folders[dr.ResID] = path.Join(path.Dir(schemaFile), dr.ResPath)
+	}
+	for column, resID := range media {
+		folder, ok := folders[resID]
+		if !ok {
+			return nil, errors.Errorf("media resource '%s' not found in dataset schema", resID)
+		}
+		media[column] = folder
+	}
+
+	return media, nil
+}
+
+// rowContent returns the row with the media file references replaced by a
+// hash of the files, since the same file name can hold different media
+// from one request to the next.
+func (m mediaColumns) rowContent(row []string) ([]string, error) {
+	if len(m) == 0 {
+		return row, nil
+	}
+
+	content := append([]string{}, row...)
+	for column, folder := range m {
+		hash, err := hashFile(path.Join(folder, row[column]))
+		if err != nil {
+			return nil, err
+		}
+		content[column] = hash
+	}
+	return content, nil
+}
+
+func hashFile(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to open media file")
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read media file")
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// cacheKey hashes the normalized row content, ignoring its index. Values are
+// keyed by their column name so rows only match if their columns do, in any
+// order.
+func cacheKey(pipelineID string, version string, columns []string, d3mIndex int, row []string) string {
+	trimmed := make([]string, len(row))
+	for i, v := range row {
+		trimmed[i] = strings.TrimSpace(v)
+	}
+	normalized := append([]string{pipelineID, version}, namedValues(columns, trimmed, d3mIndex)...)
+	return hashStrings(normalized)
+}
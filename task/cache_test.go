@@ -0,0 +1,66 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"testing"
+)
+
+func TestCacheKeyColumns(t *testing.T) {
+	base := cacheKey("p", "v", []string{"d3mIndex", "a", "b"}, 0, []string{"0", "1", "2"})
+
+	tests := []struct {
+		name    string
+		columns []string
+		row     []string
+		same    bool
+	}{
+		{"other index", []string{"d3mIndex", "a", "b"}, []string{"7", "1", "2"}, true},
+		{"padded values", []string{"d3mIndex", "a", "b"}, []string{"0", " 1", "2 "}, true},
+		{"reordered columns", []string{"b", "d3mIndex", "a"}, []string{"2", "0", "1"}, true},
+		{"other columns", []string{"d3mIndex", "b", "c"}, []string{"0", "1", "2"}, false},
+		{"swapped values", []string{"d3mIndex", "b", "a"}, []string{"0", "1", "2"}, false},
+		{"other values", []string{"d3mIndex", "a", "b"}, []string{"0", "1", "3"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index := 0
+			for i, c := range test.columns {
+				if c == "d3mIndex" {
+					index = i
+				}
+			}
+			key := cacheKey("p", "v", test.columns, index, test.row)
+			if (key == base) != test.same {
+				t.Errorf("expected the keys to match: %v", test.same)
+			}
+		})
+	}
+}
+
+func TestHashRowColumns(t *testing.T) {
+	base := hashRow([]string{"d3mIndex", "a", "b"}, []string{"0", "1", "2"})
+	if hashRow([]string{"d3mIndex", "b", "c"}, []string{"0", "1", "2"}) == base {
+		t.Errorf("expected rows of other columns to hash differently")
+	}
+	if hashRow([]string{"b", "a", "d3mIndex"}, []string{"2", "1", "0"}) != base {
+		t.Errorf("expected reordered columns to hash the same")
+	}
+	if hashRow([]string{"d3mIndex", "a", "b"}, []string{"1", "1", "2"}) == base {
+		t.Errorf("expected rows of another index to hash differently")
+	}
+}
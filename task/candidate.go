@@ -0,0 +1,155 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// CandidateOptions specifies how a candidate fit is compared to the current
+// fitted pipeline. An empty metric uses the first metric of the problem.
+type CandidateOptions struct {
+	Metric       string
+	Threshold    float64
+	HoldoutRatio float64
+	Seed         int64
+}
+
+// CandidateResult reports the comparison of a candidate fit with the current
+// fitted pipeline. CurrentScore is nil if the pipeline was not fitted yet.
+type CandidateResult struct {
+	Metric          string   `json:"metric"`
+	Threshold       float64  `json:"threshold"`
+	HoldoutCount    int      `json:"holdoutCount"`
+	CurrentScore    *float64 `json:"currentScore"`
+	CandidateScore  float64  `json:"candidateScore"`
+	Improvement     *float64 `json:"improvement"`
+	Promoted        bool     `json:"promoted"`
+	CurrentScores   []*Score `json:"currentScores,omitempty"`
+	CandidateScores []*Score `json:"candidateScores"`
+}
+
+// FitCandidate fits a candidate pipeline to a staging location and compares
+// it to the current fitted pipeline on held out data. The candidate is refit
+// on all the data and promoted only if it beats the current pipeline by
+// more than the threshold.
+func FitCandidate(ctx context.Context, pipelineID string, schemaFile string, predictionsID string, options *CandidateOptions, config *env.Config) (*CandidateResult, error) {
+	log.Infof("fitting candidate for pipeline '%s'", pipelineID)
+	ctx, span := tracing.Start(ctx, "fit candidate")
+	defer span.End()
+
+	labelled, err := loadLabelledDataset(pipelineID, schemaFile, predictionsID)
+	if err != nil {
+		return nil, err
+	}
+	defer labelled.restore()
+
+	metric := options.Metric
+	if metric == "" {
+		metrics := labelled.problem.GetMetrics()
+		if len(metrics) == 0 {
+			return nil, util.NewInvalidError("problem for pipeline '%s' does not specify a metric", pipelineID)
+		}
+		metric = metrics[0].Metric
+	}
+
+	folds, err := splitFolds(labelled.data, labelled.targetIndex, labelled.problem.IsClassification(), &ValidationOptions{
+		Method:       ValidationHoldout,
+		HoldoutRatio: options.HoldoutRatio,
+		Seed:         options.Seed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	trainData, holdoutData := splitRows(labelled.data, folds[0])
+
+	// fit the candidate on the training rows and score it
+	evaluationPath := path.Join(env.ResolvePredictionPath(predictionsID), "candidate-evaluation.d3m")
+	err = labelled.fitRows(ctx, "candidate-train", trainData, evaluationPath, config)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(evaluationPath)
+	candidateScores, err := labelled.scoreRows(ctx, "candidate-holdout", holdoutData, evaluationPath, config)
+	if err != nil {
+		return nil, err
+	}
+	candidateScore := candidateScores.GetScore(metric)
+	if candidateScore == nil {
+		return nil, util.NewInvalidError("metric '%s' could not be scored", metric)
+	}
+
+	result := &CandidateResult{
+		Metric:          metric,
+		Threshold:       options.Threshold,
+		HoldoutCount:    len(holdoutData),
+		CandidateScore:  candidateScore.Value,
+		CandidateScores: candidateScores.Scores,
+		Promoted:        true,
+	}
+
+	// score the current pipeline on the same rows
+	currentPath := env.ResolvePipelineD3MPath(pipelineID)
+	if util.FileExists(currentPath) {
+		currentScores, err := labelled.scoreRows(ctx, "current-holdout", holdoutData, currentPath, config)
+		if err != nil {
+			return nil, err
+		}
+		currentScore := currentScores.GetScore(metric)
+		if currentScore == nil {
+			return nil, util.NewInvalidError("metric '%s' could not be scored", metric)
+		}
+
+		improvement := candidateScore.Value - currentScore.Value
+		if !HigherIsBetter(metric) {
+			improvement = -improvement
+		}
+		result.CurrentScore = &currentScore.Value
+		result.CurrentScores = currentScores.Scores
+		result.Improvement = &improvement
+		result.Promoted = improvement > options.Threshold
+	}
+
+	// stage the candidate fitted on all the data
+	candidatePath := env.ResolveCandidateD3MPath(pipelineID)
+	err = labelled.fitRows(ctx, "candidate-full", labelled.data, candidatePath, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Promoted {
+		log.Infof("candidate for pipeline '%s' did not improve '%s' by more than %v and was left in '%s'",
+			pipelineID, metric, options.Threshold, candidatePath)
+		return result, nil
+	}
+
+	log.Infof("promoting candidate for pipeline '%s'", pipelineID)
+	err = os.Rename(candidatePath, currentPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to promote candidate for pipeline '%s'", pipelineID)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,250 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	checkpointDir      = "checkpoints"
+	checkpointManifest = "manifest.json"
+	checkpointFile     = "checkpoint.json"
+)
+
+// checkpointInfo identifies the fitted pipeline checkpoints were produced
+// with, so checkpoints are dropped when the pipeline gets refitted.
+type checkpointInfo struct {
+	PipelineID string    `json:"pipelineId"`
+	FittedTime time.Time `json:"fittedTime"`
+}
+
+// checkpoint is the output of a batch that was produced successfully.
+type checkpoint struct {
+	Rows   []string `json:"rows"`
+	RunIDs []string `json:"runIds"`
+
+	result  *ProduceResult
+	pending [][]string
+}
+
+// checkpoints holds the batches produced by previous attempts of a produce
+// call, so rows that were already produced are not produced again.
+type checkpoints struct {
+	path    string
+	columns []string
+	byRow   map[string]*checkpoint
+	loaded  []*checkpoint
+	resumed map[*checkpoint]bool
+}
+
+// loadCheckpoints reads the checkpoints found in the predictions folder that
+// were produced by the current fit of the pipeline. Rows are matched by the
+// values of the named columns.
+func loadCheckpoints(pipelineID string, predictionsDir string, columns []string) (*checkpoints, error) {
+	c := &checkpoints{
+		path:    path.Join(predictionsDir, checkpointDir),
+		columns: columns,
+		byRow:   make(map[string]*checkpoint),
+		resumed: make(map[*checkpoint]bool),
+	}
+
+	fittedTime, err := util.GetLastModifiedTime(env.ResolvePipelineD3MPath(pipelineID))
+	if err != nil {
+		return nil, err
+	}
+	info := &checkpointInfo{
+		PipelineID: pipelineID,
+		FittedTime: fittedTime,
+	}
+
+	// checkpoints of another pipeline or fit cannot be reused
+	previous := &checkpointInfo{}
+	manifestPath := path.Join(c.path, checkpointManifest)
+	data, err := ioutil.ReadFile(manifestPath)
+	if err == nil {
+		err = json.Unmarshal(data, previous)
+	}
+	if err != nil || previous.PipelineID != info.PipelineID || !previous.FittedTime.Equal(info.FittedTime) {
+		err = os.RemoveAll(c.path)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to remove stale checkpoints")
+		}
+		data, err = json.Marshal(info)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal checkpoint manifest")
+		}
+		err = util.WriteFileWithDirs(manifestPath, data, os.ModePerm)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to write checkpoint manifest")
+		}
+		return c, nil
+	}
+
+	directories, err := util.GetDirectories(c.path)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range directories {
+		cp, err := readCheckpoint(pipelineID, d)
+		if err != nil {
+			log.Warnf("ignoring checkpoint found in '%s': %v", d, err)
+			continue
+		}
+		c.loaded = append(c.loaded, cp)
+		for _, r := range cp.Rows {
+			c.byRow[r] = cp
+		}
+	}
+	log.Infof("loaded %d checkpoints from '%s'", len(c.loaded), c.path)
+
+	return c, nil
+}
+
+func readCheckpoint(pipelineID string, directory string) (*checkpoint, error) {
+	data, err := ioutil.ReadFile(path.Join(directory, checkpointFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read checkpoint")
+	}
+	cp := &checkpoint{}
+	err = json.Unmarshal(data, cp)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse checkpoint")
+	}
+	cp.result, err = readOutputs(pipelineID, directory)
+	if err != nil {
+		return nil, err
+	}
+	cp.result.RunIDs = cp.RunIDs
+
+	return cp, nil
+}
+
+// skip removes the rows already produced from the batch.
+func (c *checkpoints) skip(rows [][]string) [][]string {
+	remaining := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		cp, ok := c.byRow[hashRow(c.columns, row)]
+		if !ok {
+			remaining = append(remaining, row)
+			continue
+		}
+		c.resumed[cp] = true
+		cp.pending = append(cp.pending, row)
+	}
+	return remaining
+}
+
+// resume returns the results of the checkpoints whose rows were all part of
+// the request with the count of rows they hold, and the rows of the other
+// checkpoints that still need to be produced.
+func (c *checkpoints) resume() ([]*ProduceResult, int, [][]string) {
+	results := make([]*ProduceResult, 0)
+	count := 0
+	unmatched := make([][]string, 0)
+	for _, cp := range c.loaded {
+		if !c.resumed[cp] {
+			continue
+		}
+		if len(cp.pending) == len(cp.Rows) {
+			results = append(results, cp.result)
+			count = count + len(cp.Rows)
+		} else {
+			unmatched = append(unmatched, cp.pending...)
+		}
+	}
+	return results, count, unmatched
+}
+
+// save stores the result of a batch.
+func (c *checkpoints) save(rows [][]string, result *ProduceResult) error {
+	hashes := make([]string, len(rows))
+	for i, r := range rows {
+		hashes[i] = hashRow(c.columns, r)
+	}
+	sorted := append([]string{}, hashes...)
+	sort.Strings(sorted)
+	directory := path.Join(c.path, hashStrings(sorted))
+
+	for key, o := range result.Outputs {
+		err := util.WriteCSVFile(path.Join(directory, key+".csv"), o.Header, o.Data)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the checkpoint file is written last so partial checkpoints are ignored
+	data, err := json.Marshal(&checkpoint{
+		Rows:   hashes,
+		RunIDs: result.RunIDs,
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal checkpoint")
+	}
+	return util.WriteFileWithDirs(path.Join(directory, checkpointFile), data, os.ModePerm)
+}
+
+// clear removes all checkpoints.
+func (c *checkpoints) clear() error {
+	err := os.RemoveAll(c.path)
+	if err != nil {
+		return errors.Wrap(err, "unable to remove checkpoints")
+	}
+	return nil
+}
+
+// hashRow hashes the values of the row along with their column names.
+func hashRow(columns []string, row []string) string {
+	return hashStrings(namedValues(columns, row, -1))
+}
+
+// namedValues pairs the values of the row with their column names, sorted by
+// name so the layout of the columns does not matter. The value of the skipped
+// column is left out.
+func namedValues(columns []string, row []string, skip int) []string {
+	named := make([]string, 0, len(row))
+	for i, v := range row {
+		if i == skip {
+			continue
+		}
+		name := ""
+		if i < len(columns) {
+			name = columns[i]
+		}
+		named = append(named, name+"="+v)
+	}
+	sort.Strings(named)
+	return named
+}
+
+func hashStrings(values []string) string {
+	hash := sha256.Sum256([]byte(strings.Join(values, "\x1f")))
+	return hex.EncodeToString(hash[:16])
+}
@@ -17,6 +17,7 @@ package task
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"os"
@@ -24,14 +25,24 @@ import (
 
 	"github.com/pkg/errors"
 	log "github.com/unchartedsoftware/plog"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/uncharted-distil/distil-compute/metadata"
 	cm "github.com/uncharted-distil/distil-compute/model"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	"github.com/uncharted-distil/distil-pipeline-executer/dataset"
 	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
 	"github.com/uncharted-distil/distil-pipeline-executer/model"
-	"github.com/uncharted-distil/distil/api/util"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// FormatJSON is the format of datasets described as json.
+	FormatJSON = "json"
+	// FormatCSV is the format of table datasets stored as csv with a header row.
+	FormatCSV = "csv"
 )
 
 // DatasetConstructor is used to build a dataset.
@@ -42,12 +53,13 @@ type DatasetConstructor interface {
 
 // CreateDataset creates a dataset that can be used for fitting a pipeline or
 // producing predictions from a pipeline.
-func CreateDataset(pipelineID string, datasetCtor DatasetConstructor) (string, error) {
+func CreateDataset(ctx context.Context, pipelineID string, datasetCtor DatasetConstructor) (string, error) {
 	predictionsID := datasetCtor.GetPredictionsID()
+	ctx, span := tracing.Start(ctx, "create dataset", attribute.String("prediction.id", predictionsID))
+	defer span.End()
+
 	log.Infof("creating dataset for pipeline '%s' using prediction id '%s'", pipelineID, predictionsID)
-	// create the raw dataset from the input
-	datasetPath := env.ResolveDatasetPath(predictionsID)
-	dataset, err := datasetCtor.CreateDataset(datasetPath)
+	outputSchemaPath, err := FormatDataset(ctx, pipelineID, datasetCtor, env.ResolveDatasetPath(predictionsID))
 	if err != nil {
 		return "", err
 	}
@@ -57,6 +69,19 @@ func CreateDataset(pipelineID string, datasetCtor DatasetConstructor) (string, e
 	predictionsFolder := env.ResolvePredictionPath(predictionsID)
 	os.Mkdir(predictionsFolder, os.ModePerm)
 
+	return outputSchemaPath, nil
+}
+
+// FormatDataset writes the dataset as a D3M dataset folder matching the
+// schema of the pipeline, returning the path of the dataset doc.
+func FormatDataset(ctx context.Context, pipelineID string, datasetCtor DatasetConstructor, datasetPath string) (string, error) {
+	// create the raw dataset from the input
+	dataset, err := datasetCtor.CreateDataset(datasetPath)
+	if err != nil {
+		return "", err
+	}
+	metrics.AddRowsProcessed("ingest", pipelineID, len(dataset.Data))
+
 	// read the source schema doc
 	pipelinePath := env.ResolvePipelinePath(pipelineID)
 	pipelineSchemaDoc := path.Join(pipelinePath, compute.D3MDataSchema)
@@ -67,7 +92,7 @@ func CreateDataset(pipelineID string, datasetCtor DatasetConstructor) (string, e
 
 	// augment the dataset to match raw dataset columns to dataset doc variables
 	mainDR := meta.GetMainDataResource()
-	augmentedData, err := augmentPredictionDataset(dataset, mainDR.Variables)
+	augmentedData, err := augmentPredictionDataset(ctx, dataset, mainDR.Variables)
 	if err != nil {
 		return "", err
 	}
@@ -96,7 +121,10 @@ func CreateDataset(pipelineID string, datasetCtor DatasetConstructor) (string, e
 	return outputSchemaPath, nil
 }
 
-func augmentPredictionDataset(dataset *model.Dataset, variables []*cm.Variable) ([][]string, error) {
+func augmentPredictionDataset(ctx context.Context, dataset *model.Dataset, variables []*cm.Variable) ([][]string, error) {
+	_, span := tracing.Start(ctx, "augment dataset", attribute.Int("rows", len(dataset.Data)))
+	defer span.End()
+
 	log.Infof("augmenting data fields with schema variables")
 
 	// map fields to indices
@@ -153,7 +181,9 @@ func augmentPredictionDataset(dataset *model.Dataset, variables []*cm.Variable)
 	return output, nil
 }
 
-// ClearDataset deletes the dataset and prediction data.
+// ClearDataset deletes the dataset and prediction data. Checkpoints are kept
+// since they are only left behind when rows failed and need to be produced
+// again.
 func ClearDataset(pipelineID string, predictionID string) error {
 	log.Infof("clearing dataset and prediction info for pipeline '%s' and prediction '%s'", pipelineID, predictionID)
 	predictionsDir := env.ResolvePredictionPath(predictionID)
@@ -161,7 +191,7 @@ func ClearDataset(pipelineID string, predictionID string) error {
 
 	// delete dataset directory & prediction directory
 	log.Infof("deleting prediction content found in '%s'", predictionsDir)
-	err := util.RemoveContents(predictionsDir)
+	err := util.RemoveContents(predictionsDir, checkpointDir)
 	if err != nil {
 		return err
 	}
@@ -173,11 +203,37 @@ func ClearDataset(pipelineID string, predictionID string) error {
 	return nil
 }
 
+// ParseDataset parses the raw data into the type of dataset expected by the
+// pipeline. Only table datasets can be read from csv.
+func ParseDataset(ctx context.Context, pipelineID string, data []byte, format string) (DatasetConstructor, error) {
+	_, span := tracing.Start(ctx, "parse dataset", attribute.Int("bytes", len(data)))
+	defer span.End()
+
+	datasetType, err := GetDatasetType(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case format == FormatJSON && datasetType == dataset.ImageType:
+		return dataset.NewImageDataset(data)
+	case format == FormatJSON && datasetType == dataset.TableType:
+		return dataset.NewTableDataset(data)
+	case format == FormatCSV && datasetType == dataset.TableType:
+		return dataset.NewTableDatasetFromCSV("", data)
+	default:
+		return nil, util.NewInvalidError("unsupported dataset type '%s' for format '%s'", datasetType, format)
+	}
+}
+
 // GetDatasetType returns the type of dataset on which the specified pipeline acts.
 func GetDatasetType(pipelineID string) (dataset.Type, error) {
 	// load the metadata for the pipeline dataset
 	pipelinePath := env.ResolvePipelinePath(pipelineID)
 	pipelineSchemaDoc := path.Join(pipelinePath, compute.D3MDataSchema)
+	if !util.FileExists(pipelineSchemaDoc) {
+		return dataset.UnknownType, util.NewNotFoundError("pipeline '%s' not found", pipelineID)
+	}
 	meta, err := metadata.LoadMetadataFromOriginalSchema(pipelineSchemaDoc, false)
 	if err != nil {
 		return dataset.UnknownType, err
@@ -195,5 +251,80 @@ func GetDatasetType(pipelineID string) (dataset.Type, error) {
 		}
 	}
 
-	return dataset.UnknownType, errors.New("unsupported dataset type")
+	return dataset.UnknownType, util.NewInvalidError("unsupported dataset type")
+}
+
+// SplitLabels blanks the target values of the dataset rows, returning the
+// unlabelled rows along with the labels keyed by d3m index.
+func SplitLabels(schemaFile string, data [][]string, target *ProblemTarget) ([][]string, map[string]string, error) {
+	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFile, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetIndex, d3mIndex, err := getLabelIndices(meta, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return splitLabelRows(data, targetIndex, d3mIndex)
+}
+
+func splitLabelRows(data [][]string, targetIndex int, d3mIndex int) ([][]string, map[string]string, error) {
+	unlabelled := make([][]string, len(data))
+	labels := make(map[string]string)
+	for i, row := range data {
+		if len(row) <= targetIndex || len(row) <= d3mIndex {
+			return nil, nil, util.NewInvalidError("row %d is missing the target or index field", i)
+		}
+		labels[row[d3mIndex]] = row[targetIndex]
+		unlabelledRow := make([]string, len(row))
+		copy(unlabelledRow, row)
+		unlabelledRow[targetIndex] = ""
+		unlabelled[i] = unlabelledRow
+	}
+
+	return unlabelled, labels, nil
+}
+
+// getLabelIndices returns the column indices of the target and the d3m index
+// in the main data resource.
+func getLabelIndices(meta *cm.Metadata, target *ProblemTarget) (int, int, error) {
+	targetIndex := -1
+	d3mIndex := -1
+	for _, v := range meta.GetMainDataResource().Variables {
+		if v.Name == target.ColName || v.DisplayName == target.ColName {
+			targetIndex = v.Index
+		}
+		if v.Name == cm.D3MIndexName {
+			d3mIndex = v.Index
+		}
+	}
+	if targetIndex < 0 {
+		return -1, -1, util.NewInvalidError("target '%s' not found in dataset", target.ColName)
+	}
+	if d3mIndex < 0 {
+		return -1, -1, errors.Errorf("d3m index not found in dataset")
+	}
+
+	return targetIndex, d3mIndex, nil
+}
+
+// ReadLearningData reads the rows of the main data resource of a dataset,
+// skipping the header.
+func ReadLearningData(schemaFile string) ([][]string, error) {
+	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFile, false)
+	if err != nil {
+		return nil, err
+	}
+
+	mainDR := meta.GetMainDataResource()
+	dataFilename := path.Join(path.Dir(schemaFile), mainDR.ResPath)
+
+	data, err := util.ReadCSVFile(dataFilename, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
 }
@@ -16,38 +16,101 @@
 package task
 
 import (
-	"bytes"
-	"fmt"
-	"os/exec"
+	"context"
 
-	"github.com/pkg/errors"
 	log "github.com/unchartedsoftware/plog"
 
 	"github.com/uncharted-distil/distil-pipeline-executer/env"
 )
 
-// Fit trains the specified model using the provided labelled data.
-func Fit(pipelineID string, schemaFile string, predictionsID string, config *env.Config) error {
+// FitOptions specifies how a dataset is fit. Validation is skipped if nil
+// and the fitted pipeline is replaced directly if the candidate is nil.
+type FitOptions struct {
+	Training   string
+	Validation *ValidationOptions
+	Candidate  *CandidateOptions
+}
+
+// FitResult holds the outcome of fitting a dataset. The run id is only set
+// when the pipeline is fit directly rather than as a candidate.
+type FitResult struct {
+	PredictionsID string
+	TrainingRows  int
+	Validation    *ValidationResult
+	Candidate     *CandidateResult
+	Fitted        bool
+	RunID         string
+}
+
+// FitDataset creates the dataset, accumulates it into the training data,
+// validates the pipeline on it and fits the pipeline as requested by the
+// options.
+func FitDataset(ctx context.Context, pipelineID string, ds DatasetConstructor, options *FitOptions, config *env.Config) (*FitResult, error) {
+	err := CheckTrainingMode(options.Training)
+	if err != nil {
+		return nil, err
+	}
+	result := &FitResult{PredictionsID: ds.GetPredictionsID()}
+
+	schemaPath, err := CreateDataset(ctx, pipelineID, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	// fit on all the training data received so far if requested
+	if options.Training != TrainingNone {
+		result.TrainingRows, err = AccumulateTrainingData(ctx, pipelineID, schemaPath, options.Training == TrainingReplace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// validate on splits of the data before fitting on all of it
+	if options.Validation != nil && options.Validation.Method != ValidationNone {
+		result.Validation, err = Validate(ctx, pipelineID, schemaPath, ds.GetPredictionsID(), options.Validation, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Candidate != nil {
+		// only replace the fitted pipeline if the candidate is better
+		result.Candidate, err = FitCandidate(ctx, pipelineID, schemaPath, ds.GetPredictionsID(), options.Candidate, config)
+		if err != nil {
+			return nil, err
+		}
+		result.Fitted = result.Candidate.Promoted
+		return result, nil
+	}
+
+	result.RunID, err = Fit(ctx, pipelineID, schemaPath, ds.GetPredictionsID(), config)
+	if err != nil {
+		return nil, err
+	}
+	result.Fitted = true
+
+	return result, nil
+}
+
+// Fit trains the specified model using the provided labelled data, returning
+// the id of the run.
+func Fit(ctx context.Context, pipelineID string, schemaFile string, predictionsID string, config *env.Config) (string, error) {
+	return fit(ctx, pipelineID, schemaFile, env.ResolvePipelineD3MPath(pipelineID), config)
+}
+
+// fit trains the specified model, writing the fitted pipeline to the output path.
+func fit(ctx context.Context, pipelineID string, schemaFile string, outputPath string, config *env.Config) (string, error) {
 	// run the fit command
-	log.Infof("running fit command using shell")
-	outputPath := env.ResolvePipelineD3MPath(pipelineID)
-	commandLine := fmt.Sprintf("python3 runner.py runtime -v %s fit -r %s -i %s -p %s -s %s",
-		config.D3MStaticDir, env.ResolveProblemPath(pipelineID), schemaFile,
-		env.ResolvePipelineJSONPath(pipelineID), outputPath)
-	cmd := exec.Command("/bin/sh", "-c", commandLine)
-
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	log.Infof("out: %s", stdout.String())
+	log.Infof("running fit command")
+	run, err := runRunner(ctx, pipelineID, "fit", func(pipelineRunPath string) []string {
+		return []string{"runtime", "-v", config.D3MStaticDir, "fit",
+			"-r", env.ResolveProblemPath(pipelineID), "-i", schemaFile,
+			"-p", env.ResolvePipelineJSONPath(pipelineID), "-s", outputPath, "-O", pipelineRunPath}
+	}, config)
 	if err != nil {
-		log.Errorf("err: %s", stderr.String())
-		return errors.Wrap(err, "unable to run fit command")
+		return "", err
 	}
 	log.Infof("wrote trained pipeline to '%s'", outputPath)
 
-	return nil
+	return run.RunID, nil
 }
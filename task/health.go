@@ -0,0 +1,227 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-compute/metadata"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// pythonCheckTTL is how long the result of the python check is reused
+	// since importing d3m takes a few seconds.
+	pythonCheckTTL     = time.Minute
+	pythonCheckTimeout = 30 * time.Second
+)
+
+var (
+	pythonCheckMutex  sync.Mutex
+	pythonCheckTime   time.Time
+	pythonCheckResult *Check
+)
+
+// Check is the outcome of a single readiness check.
+type Check struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// Readiness is the outcome of all the readiness checks.
+type Readiness struct {
+	Ready  bool     `json:"ready"`
+	Checks []*Check `json:"checks"`
+}
+
+// CheckReadiness verifies the service has everything it needs to fit and
+// produce: writable folders, a python runtime with the d3m package, the
+// static resources and at least one loadable pipeline.
+func CheckReadiness(ctx context.Context, config *env.Config) *Readiness {
+	checks := []*Check{
+		newCheck("dataset directory writable", checkWritable(config.DatasetDir)),
+		newCheck("prediction directory writable", checkWritable(config.PredictionDir)),
+		newCheck("pipeline directory writable", checkWritable(config.PipelineDir)),
+		newCheck("run directory writable", checkWritable(config.RunDir)),
+		newCheck("job directory writable", checkWritable(config.JobDir)),
+		newCheck("static resources present", checkDirectory(config.D3MStaticDir)),
+		newCheck("runner script present", checkFile(config.RunnerScript)),
+		checkPython(ctx, config),
+		checkPipelines(config.PipelineDir),
+	}
+
+	ready := true
+	for _, c := range checks {
+		ready = ready && c.OK
+	}
+
+	return &Readiness{
+		Ready:  ready,
+		Checks: checks,
+	}
+}
+
+func newCheck(name string, err error) *Check {
+	check := &Check{
+		Name: name,
+		OK:   err == nil,
+	}
+	if err != nil {
+		check.Message = err.Error()
+	}
+	return check
+}
+
+func checkDirectory(directory string) error {
+	info, err := os.Stat(directory)
+	if err != nil {
+		return errors.Wrapf(err, "unable to access '%s'", directory)
+	}
+	if !info.IsDir() {
+		return errors.Errorf("'%s' is not a directory", directory)
+	}
+
+	return nil
+}
+
+func checkFile(filename string) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return errors.Wrapf(err, "unable to access '%s'", filename)
+	}
+	if info.IsDir() {
+		return errors.Errorf("'%s' is a directory", filename)
+	}
+
+	return nil
+}
+
+func checkWritable(directory string) error {
+	err := os.MkdirAll(directory, os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create '%s'", directory)
+	}
+
+	file, err := ioutil.TempFile(directory, ".readyz-")
+	if err != nil {
+		return errors.Wrapf(err, "unable to write to '%s'", directory)
+	}
+	file.Close()
+
+	return os.Remove(file.Name())
+}
+
+// checkPipelines loads every pipeline stored in the directory, passing if
+// at least one of them loads and listing the ones that do not.
+func checkPipelines(directory string) *Check {
+	check := &Check{Name: "pipeline loadable"}
+	directories, err := util.GetDirectories(directory)
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+
+	loaded := 0
+	failures := make([]string, 0)
+	for _, d := range directories {
+		pipelineID := path.Base(d)
+		err = loadPipeline(d, pipelineID)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("'%s': %v", pipelineID, err))
+		} else {
+			loaded++
+		}
+	}
+
+	check.OK = loaded > 0
+	check.Message = fmt.Sprintf("%d of %d pipelines loadable", loaded, len(directories))
+	if len(failures) > 0 {
+		check.Message = fmt.Sprintf("%s, unable to load %s", check.Message, strings.Join(failures, "; "))
+	}
+
+	return check
+}
+
+// loadPipeline reads and parses the dataset schema, pipeline and problem
+// stored in the pipeline directory.
+func loadPipeline(directory string, pipelineID string) error {
+	isPipeline, _ := util.IsPipelineDirectory(directory)
+	if !isPipeline {
+		return errors.New("missing dataset schema, problem or pipeline")
+	}
+	_, err := metadata.LoadMetadataFromOriginalSchema(path.Join(directory, compute.D3MDataSchema), false)
+	if err != nil {
+		return errors.Wrap(err, "unable to load dataset schema")
+	}
+	_, err = GetPipelineOutputNames(pipelineID)
+	if err != nil {
+		return err
+	}
+	_, err = LoadProblem(pipelineID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func checkPython(ctx context.Context, config *env.Config) *Check {
+	pythonCheckMutex.Lock()
+	defer pythonCheckMutex.Unlock()
+
+	if pythonCheckResult != nil && time.Since(pythonCheckTime) < pythonCheckTTL {
+		return pythonCheckResult
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, pythonCheckTimeout)
+	defer cancel()
+
+	check := &Check{Name: "python d3m import"}
+	cmd := exec.CommandContext(checkCtx, config.RunnerPython, "-c", "import d3m; print(d3m.__version__)")
+	cmd.Env = runnerEnvironment(config)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		summary := summarizeTraceback(string(output))
+		if summary == "" {
+			summary = strings.TrimSpace(string(output))
+		}
+		check.Message = strings.TrimSuffix(fmt.Sprintf("%v: %s", err, summary), ": ")
+	} else {
+		check.OK = true
+		check.Message = fmt.Sprintf("d3m %s", strings.TrimSpace(string(output)))
+	}
+
+	// do not cache checks interrupted by the caller
+	if ctx.Err() == nil {
+		pythonCheckResult = check
+		pythonCheckTime = time.Now()
+	}
+
+	return check
+}
@@ -0,0 +1,67 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/internal/testrunner"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+func TestCheckPipelines(t *testing.T) {
+	err := os.MkdirAll(testConfig.PipelineDir, os.ModePerm)
+	if err != nil {
+		t.Fatalf("unable to create pipeline directory: %v", err)
+	}
+	defer util.RemoveContents(testConfig.PipelineDir)
+
+	check := checkPipelines(testConfig.PipelineDir)
+	if check.OK {
+		t.Errorf("expected the check to fail without pipelines")
+	}
+
+	err = StorePipeline("broken", []byte(testrunner.Pipeline), []byte(testrunner.DatasetSchema), []byte("{"), false)
+	if err != nil {
+		t.Fatalf("unable to store pipeline: %v", err)
+	}
+	check = checkPipelines(testConfig.PipelineDir)
+	if check.OK {
+		t.Errorf("expected the check to fail without loadable pipelines")
+	}
+	if !strings.Contains(check.Message, "'broken'") {
+		t.Errorf("expected the broken pipeline to be reported, got '%s'", check.Message)
+	}
+
+	err = StorePipeline("valid", []byte(testrunner.Pipeline), []byte(testrunner.DatasetSchema), []byte(testrunner.Problem), false)
+	if err != nil {
+		t.Fatalf("unable to store pipeline: %v", err)
+	}
+	err = os.MkdirAll(env.ResolvePipelinePath("empty"), os.ModePerm)
+	if err != nil {
+		t.Fatalf("unable to create pipeline: %v", err)
+	}
+	check = checkPipelines(testConfig.PipelineDir)
+	if !check.OK {
+		t.Errorf("expected the check to pass with a loadable pipeline, got '%s'", check.Message)
+	}
+	if !strings.Contains(check.Message, "1 of 3") || !strings.Contains(check.Message, "'broken'") || !strings.Contains(check.Message, "'empty'") {
+		t.Errorf("expected the unloadable pipelines to be reported, got '%s'", check.Message)
+	}
+}
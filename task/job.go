@@ -0,0 +1,356 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// JobPending is the status of a job waiting to be admitted.
+	JobPending JobStatus = "pending"
+	// JobRunning is the status of a job being processed.
+	JobRunning JobStatus = "running"
+	// JobSucceeded is the status of a job that completed.
+	JobSucceeded JobStatus = "succeeded"
+	// JobFailed is the status of a job that failed or was interrupted.
+	JobFailed JobStatus = "failed"
+
+	// JobFit is the type of fit jobs.
+	JobFit = "fit"
+	// JobProduce is the type of produce jobs.
+	JobProduce = "produce"
+	// JobScore is the type of score jobs.
+	JobScore = "score"
+
+	jobFile         = "job.json"
+	interruptionMsg = "interrupted by a service restart"
+)
+
+// JobStatus is the state of a job.
+type JobStatus string
+
+type jobContextKey struct{}
+
+// Job tracks a fit, produce or score request on disk so its outcome is known
+// even if the service restarts while processing it.
+type Job struct {
+	JobID         string    `json:"jobId"`
+	Type          string    `json:"type"`
+	PipelineID    string    `json:"pipelineId"`
+	PredictionsID string    `json:"predictionId,omitempty"`
+	Status        JobStatus `json:"status"`
+	Error         string    `json:"error,omitempty"`
+	RowsTotal     int       `json:"rowsTotal"`
+	RowsProcessed int       `json:"rowsProcessed"`
+	RowsFailed    int       `json:"rowsFailed"`
+	RunIDs        []string  `json:"runIds,omitempty"`
+	CreatedTime   time.Time `json:"createdTime"`
+	UpdatedTime   time.Time `json:"updatedTime"`
+
+	store *JobStore
+	mutex sync.Mutex
+}
+
+// JobStore persists jobs under a folder.
+type JobStore struct {
+	directory string
+	mutex     sync.Mutex
+}
+
+// NewJobStore creates a job store using the specified folder.
+func NewJobStore(directory string) (*JobStore, error) {
+	err := os.MkdirAll(directory, os.ModePerm)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create job folder")
+	}
+
+	return &JobStore{
+		directory: directory,
+	}, nil
+}
+
+// NewJobContext returns a context tracking work under the job.
+func NewJobContext(ctx context.Context, job *Job) context.Context {
+	return context.WithValue(ctx, jobContextKey{}, job)
+}
+
+// JobFromContext returns the job of the context, or nil if the work is not
+// tracked.
+func JobFromContext(ctx context.Context) *Job {
+	job, _ := ctx.Value(jobContextKey{}).(*Job)
+	return job
+}
+
+// Create stores a new pending job.
+func (s *JobStore) Create(jobID string, jobType string, pipelineID string) (*Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := env.ValidateID(jobID)
+	if err != nil {
+		return nil, util.WithKind(err, util.KindBadRequest)
+	}
+	if util.FileExists(s.jobPath(jobID)) {
+		return nil, util.WithKind(errors.Errorf("job '%s' already exists", jobID), util.KindConflict)
+	}
+
+	now := time.Now()
+	job := &Job{
+		JobID:       jobID,
+		Type:        jobType,
+		PipelineID:  pipelineID,
+		Status:      JobPending,
+		CreatedTime: now,
+		UpdatedTime: now,
+		store:       s,
+	}
+	err = s.write(job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Get returns the job with the specified id.
+func (s *JobStore) Get(jobID string) (*Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.read(jobID)
+}
+
+// List returns all stored jobs, most recent first.
+func (s *JobStore) List() ([]*Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	directories, err := util.GetDirectories(s.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0)
+	for _, d := range directories {
+		job, err := s.read(path.Base(d))
+		if err != nil {
+			log.Warnf("skipping job found in '%s': %v", d, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedTime.After(jobs[j].CreatedTime)
+	})
+
+	return jobs, nil
+}
+
+// Recover fails the jobs that were pending or running when the service
+// stopped. Their pending batches are deliberately not persisted and resumed:
+// requests are served synchronously, so once the service stops no client is
+// left waiting for the results. A client retrying a produce request with the
+// same predictions id resumes from the batch checkpoints instead.
+func (s *JobStore) Recover() error {
+	jobs, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.Status != JobPending && job.Status != JobRunning {
+			continue
+		}
+		log.Warnf("marking %s job '%s' of pipeline '%s' as failed since it was %s on shutdown",
+			job.Type, job.JobID, job.PipelineID, job.Status)
+		err = job.Finish(errors.New(interruptionMsg))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Prune removes the jobs that finished longer than retention ago and
+// returns how many were removed. Pending and running jobs are never removed.
+func (s *JobStore) Prune(retention time.Duration) (int, error) {
+	jobs, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cutoff := time.Now().Add(-retention)
+	removed := 0
+	for _, job := range jobs {
+		if job.Status == JobPending || job.Status == JobRunning || job.UpdatedTime.After(cutoff) {
+			continue
+		}
+		err = os.RemoveAll(path.Join(s.directory, job.JobID))
+		if err != nil {
+			return removed, errors.Wrapf(err, "unable to remove job '%s'", job.JobID)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+func (s *JobStore) jobPath(jobID string) string {
+	return path.Join(s.directory, jobID, jobFile)
+}
+
+func (s *JobStore) read(jobID string) (*Job, error) {
+	if !env.IsValidID(jobID) {
+		return nil, util.NewNotFoundError("job '%s' not found", jobID)
+	}
+	data, err := ioutil.ReadFile(s.jobPath(jobID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, util.NewNotFoundError("job '%s' not found", jobID)
+		}
+		return nil, errors.Wrapf(err, "unable to read job '%s'", jobID)
+	}
+
+	job := &Job{}
+	err = json.Unmarshal(data, job)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse job '%s'", jobID)
+	}
+	job.store = s
+
+	return job, nil
+}
+
+// write replaces the job file atomically so a crash never leaves it partial.
+func (s *JobStore) write(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrapf(err, "unable to marshal job '%s'", job.JobID)
+	}
+
+	filename := s.jobPath(job.JobID)
+	err = util.WriteFileWithDirs(filename+".tmp", data, os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "unable to write job '%s'", job.JobID)
+	}
+	err = os.Rename(filename+".tmp", filename)
+	if err != nil {
+		return errors.Wrapf(err, "unable to replace job '%s'", job.JobID)
+	}
+
+	return nil
+}
+
+// update applies the change to the job and persists it.
+func (j *Job) update(change func()) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	change()
+	j.UpdatedTime = time.Now()
+
+	j.store.mutex.Lock()
+	defer j.store.mutex.Unlock()
+	return j.store.write(j)
+}
+
+// Start flags the job as running.
+func (j *Job) Start() error {
+	return j.update(func() {
+		j.Status = JobRunning
+	})
+}
+
+// Finish flags the job as succeeded if err is nil and as failed otherwise.
+func (j *Job) Finish(err error) error {
+	return j.update(func() {
+		if err != nil {
+			j.Status = JobFailed
+			j.Error = err.Error()
+		} else {
+			j.Status = JobSucceeded
+		}
+	})
+}
+
+// setRows records the number of rows the job has to process.
+func (j *Job) setRows(predictionsID string, total int) error {
+	return j.update(func() {
+		j.PredictionsID = predictionsID
+		j.RowsTotal = total
+		j.RowsProcessed = 0
+		j.RowsFailed = 0
+	})
+}
+
+// addProgress records rows as processed, some of which failed.
+func (j *Job) addProgress(count int, failed int) error {
+	return j.update(func() {
+		j.RowsProcessed = j.RowsProcessed + count
+		j.RowsFailed = j.RowsFailed + failed
+	})
+}
+
+// addRun records a runner invocation done as part of the job.
+func (j *Job) addRun(runID string) error {
+	return j.update(func() {
+		j.RunIDs = append(j.RunIDs, runID)
+	})
+}
+
+// EnqueueRows queues the rows of a dataset to produce, with the priority
+// specified, and records their count with the job tracking the work if any.
+func EnqueueRows(ctx context.Context, queue *Queue, predictionsID string, priority int, rows [][]string) error {
+	err := JobFromContext(ctx).setRows(predictionsID, len(rows))
+	if err != nil {
+		return err
+	}
+
+	err = queue.AddDataset(predictionsID, priority)
+	if err != nil {
+		return err
+	}
+	err = queue.AddEntries(predictionsID, rows)
+	if err != nil {
+		queue.RemoveDataset(predictionsID)
+		return err
+	}
+	queue.CloseDataset(predictionsID)
+
+	return nil
+}
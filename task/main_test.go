@@ -0,0 +1,57 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+var (
+	testConfig env.Config
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "task-test")
+	if err != nil {
+		panic(err)
+	}
+
+	log.SetLevel(log.WarnLevel)
+	testConfig, err = env.LoadConfig()
+	if err != nil {
+		panic(err)
+	}
+	testConfig.PipelineDir = path.Join(dir, "pipelines")
+	testConfig.DatasetDir = path.Join(dir, "datasets")
+	testConfig.PredictionDir = path.Join(dir, "predictions")
+	testConfig.RunDir = path.Join(dir, "runs")
+	testConfig.JobDir = path.Join(dir, "jobs")
+	env.Initialize(&testConfig)
+	util.SetConfig(&testConfig)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+
+	os.Exit(code)
+}
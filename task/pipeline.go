@@ -16,6 +16,9 @@
 package task
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"time"
@@ -78,6 +81,11 @@ func GetPipelines(directory string) ([]*PipelineInfo, error) {
 	return pipelines, nil
 }
 
+// IsFitted returns true if the pipeline has been fitted.
+func IsFitted(pipelineID string) bool {
+	return util.FileExists(env.ResolvePipelineD3MPath(pipelineID))
+}
+
 // StorePipeline stores a pipeline to disk for future use.
 func StorePipeline(pipelineID string, pipeline []byte, datasetSchema []byte, problem []byte, overwrite bool) error {
 	log.Infof("storing pipeline with id '%s'", pipelineID)
@@ -89,7 +97,7 @@ func StorePipeline(pipelineID string, pipeline []byte, datasetSchema []byte, pro
 	// check if already there and if not set to overwrite then error
 	if util.FileExists(schemaPath) {
 		if !overwrite {
-			return errors.Errorf("pipeline '%s' already exists", pipelineID)
+			return util.WithKind(errors.Errorf("pipeline '%s' already exists", pipelineID), util.KindConflict)
 		}
 
 		// remove existing pipeline and recreate folder
@@ -139,3 +147,30 @@ func UpdatePipeline(pipelineID string, pipeline []byte, isFitted bool) error {
 
 	return nil
 }
+
+// GetPipelineOutputNames returns the names of the outputs exposed by the
+// pipeline, keyed by the output key used by the runtime (ie: outputs.0).
+func GetPipelineOutputNames(pipelineID string) (map[string]string, error) {
+	pipelinePath := env.ResolvePipelineJSONPath(pipelineID)
+	data, err := ioutil.ReadFile(pipelinePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read pipeline '%s'", pipelineID)
+	}
+
+	description := struct {
+		Outputs []struct {
+			Name string `json:"name"`
+		} `json:"outputs"`
+	}{}
+	err = json.Unmarshal(data, &description)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse pipeline '%s'", pipelineID)
+	}
+
+	names := make(map[string]string)
+	for i, o := range description.Outputs {
+		names[fmt.Sprintf("outputs.%d", i)] = o.Name
+	}
+
+	return names, nil
+}
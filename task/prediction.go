@@ -0,0 +1,106 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prediction is a result from a produce call. Confidence and Probabilities
+// are only set when the pipeline exposes them.
+type Prediction struct {
+	ID            string             `json:"id"`
+	Value         string             `json:"value"`
+	Confidence    *float64           `json:"confidence,omitempty"`
+	Probabilities map[string]float64 `json:"probabilities,omitempty"`
+}
+
+// ParsePredictions builds the predictions from the main pipeline output. If
+// the output has a confidence column, rows sharing an id are treated as the
+// probabilities of each class and the most likely class is used as value.
+func ParsePredictions(predictions *Output) ([]*Prediction, error) {
+	output := make([]*Prediction, 0)
+	if predictions == nil {
+		return output, nil
+	}
+
+	confidenceIndex := -1
+	for i, c := range predictions.Header {
+		if strings.ToLower(c) == "confidence" {
+			confidenceIndex = i
+		}
+	}
+
+	predictionsByID := make(map[string]*Prediction)
+	for _, p := range predictions.Data {
+		if len(p) < 2 {
+			return nil, errors.Errorf("prediction row has %d fields when at least 2 are expected", len(p))
+		}
+		if confidenceIndex < 0 {
+			output = append(output, &Prediction{
+				ID:    p[0],
+				Value: p[1],
+			})
+			continue
+		}
+
+		confidence, err := strconv.ParseFloat(p[confidenceIndex], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse confidence of prediction '%s'", p[0])
+		}
+		prediction := predictionsByID[p[0]]
+		if prediction == nil {
+			prediction = &Prediction{
+				ID:            p[0],
+				Value:         p[1],
+				Confidence:    &confidence,
+				Probabilities: make(map[string]float64),
+			}
+			predictionsByID[p[0]] = prediction
+			output = append(output, prediction)
+		} else if confidence > *prediction.Confidence {
+			prediction.Value = p[1]
+			prediction.Confidence = &confidence
+		}
+		prediction.Probabilities[p[1]] = confidence
+	}
+
+	// a single row per id only provides a confidence
+	for _, p := range output {
+		if len(p.Probabilities) == 1 {
+			p.Probabilities = nil
+		}
+	}
+
+	return output, nil
+}
+
+// GetPredictedValues returns the predicted value of every row, keyed by id.
+func GetPredictedValues(predictions *Output) (map[string]string, error) {
+	parsed, err := ParsePredictions(predictions)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, p := range parsed {
+		values[p.ID] = p.Value
+	}
+	return values, nil
+}
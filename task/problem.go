@@ -0,0 +1,119 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// Problem captures the parts of a D3M problem document needed to evaluate
+// a pipeline.
+type Problem struct {
+	About struct {
+		ProblemID    string   `json:"problemID"`
+		TaskType     string   `json:"taskType"`
+		TaskKeywords []string `json:"taskKeywords"`
+	} `json:"about"`
+	Inputs struct {
+		Data []struct {
+			DatasetID string           `json:"datasetID"`
+			Targets   []*ProblemTarget `json:"targets"`
+		} `json:"data"`
+		PerformanceMetrics []*ProblemMetric `json:"performanceMetrics"`
+	} `json:"inputs"`
+}
+
+// ProblemTarget is a target variable of a problem.
+type ProblemTarget struct {
+	TargetIndex int    `json:"targetIndex"`
+	ResID       string `json:"resID"`
+	ColIndex    int    `json:"colIndex"`
+	ColName     string `json:"colName"`
+}
+
+// ProblemMetric is a performance metric used to evaluate a problem.
+type ProblemMetric struct {
+	Metric string                 `json:"metric"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// LoadProblem reads the problem document stored with the pipeline.
+func LoadProblem(pipelineID string) (*Problem, error) {
+	problemPath := env.ResolveProblemPath(pipelineID)
+	data, err := ioutil.ReadFile(problemPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, util.NewNotFoundError("pipeline '%s' not found", pipelineID)
+		}
+		return nil, errors.Wrapf(err, "unable to read problem for pipeline '%s'", pipelineID)
+	}
+
+	problem := &Problem{}
+	err = json.Unmarshal(data, problem)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse problem for pipeline '%s'", pipelineID)
+	}
+
+	return problem, nil
+}
+
+// IsClassification returns true if the problem is a classification task.
+func (p *Problem) IsClassification() bool {
+	if strings.ToLower(p.About.TaskType) == "classification" {
+		return true
+	}
+	for _, k := range p.About.TaskKeywords {
+		if strings.ToLower(k) == "classification" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTarget returns the single target of the problem.
+func (p *Problem) GetTarget() (*ProblemTarget, error) {
+	if len(p.Inputs.Data) == 0 || len(p.Inputs.Data[0].Targets) == 0 {
+		return nil, util.NewInvalidError("problem does not specify a target")
+	}
+	if len(p.Inputs.Data) > 1 || len(p.Inputs.Data[0].Targets) > 1 {
+		return nil, util.NewInvalidError("problems with multiple targets are not supported")
+	}
+
+	return p.Inputs.Data[0].Targets[0], nil
+}
+
+// GetMetrics returns the performance metrics of the problem.
+func (p *Problem) GetMetrics() []*ProblemMetric {
+	return p.Inputs.PerformanceMetrics
+}
+
+// getParam returns a metric parameter as a string.
+func (m *ProblemMetric) getParam(name string) string {
+	value, ok := m.Params[name]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
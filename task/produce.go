@@ -17,119 +17,581 @@ package task
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/unchartedsoftware/plog"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/uncharted-distil/distil-compute/metadata"
 	"github.com/uncharted-distil/distil-compute/model"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
 	"github.com/uncharted-distil/distil-pipeline-executer/util"
 )
 
+const (
+	// PredictionsOutputKey is the key of the output holding the predictions.
+	PredictionsOutputKey = "outputs.0"
+)
+
+// ProduceDataset creates the dataset and queues its rows to produce
+// predictions for them using the fitted pipeline, returning the outputs
+// along with the parsed predictions.
+func ProduceDataset(ctx context.Context, pipelineID string, ds DatasetConstructor, queue *Queue, priority int, config *env.Config) (*ProduceResult, []*Prediction, error) {
+	if !IsFitted(pipelineID) {
+		return nil, nil, util.WithKind(errors.Errorf("pipeline '%s' has not been fitted", pipelineID), util.KindConflict)
+	}
+
+	schemaPath, err := CreateDataset(ctx, pipelineID, ds)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := ReadLearningData(schemaPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// queue the rows, served according to the request priority
+	err = EnqueueRows(ctx, queue, ds.GetPredictionsID(), priority, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// run predictions on the newly created dataset
+	result, err := ProduceBatch(ctx, pipelineID, schemaPath, ds.GetPredictionsID(), queue, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	predictions, err := ParsePredictions(result.Predictions())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if config.ClearDataset {
+		err = ClearDataset(pipelineID, ds.GetPredictionsID())
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "unable to clear produce dataset")
+		}
+	}
+
+	return result, predictions, nil
+}
+
+// Output holds the content of a single output exposed by a pipeline.
+type Output struct {
+	Key    string     `json:"key"`
+	Name   string     `json:"name"`
+	Header []string   `json:"columns"`
+	Data   [][]string `json:"rows"`
+}
+
+// ProduceResult holds all the outputs written by a produce call, keyed by
+// output key (ie: outputs.0).
+type ProduceResult struct {
+	Outputs  map[string]*Output
+	RunIDs   []string
+	Failures []*RowFailure
+}
+
+// Predictions returns the main predictions output of the pipeline.
+func (r *ProduceResult) Predictions() *Output {
+	return r.Outputs[PredictionsOutputKey]
+}
+
+func (r *ProduceResult) merge(other *ProduceResult) {
+	r.RunIDs = append(r.RunIDs, other.RunIDs...)
+	r.Failures = append(r.Failures, other.Failures...)
+	for key, o := range other.Outputs {
+		existing := r.Outputs[key]
+		if existing == nil {
+			r.Outputs[key] = o
+		} else {
+			existing.Data = append(existing.Data, o.Data...)
+		}
+	}
+}
+
 // Produce produces predictions using the specified model and input data.
-func Produce(pipelineID string, schemaFile string, predictionsID string, config *env.Config) ([][]string, error) {
+func Produce(ctx context.Context, pipelineID string, schemaFile string, predictionsID string, config *env.Config) (*ProduceResult, error) {
+	return produce(ctx, pipelineID, env.ResolvePipelineD3MPath(pipelineID), schemaFile, predictionsID, config)
+}
+
+// produce produces predictions using the fitted pipeline found at the
+// specified path.
+func produce(ctx context.Context, pipelineID string, fittedPath string, schemaFile string, predictionsID string, config *env.Config) (*ProduceResult, error) {
 	// run the produce command
-	log.Infof("running produce command using shell")
+	log.Infof("running produce command")
 
 	// need to make the output folder for the predictions
 	predictionsDir := env.ResolvePredictionPath(predictionsID)
-	predictionOutput := path.Join(predictionsDir, "outputs.0.csv")
+	predictionOutput := path.Join(predictionsDir, fmt.Sprintf("%s.csv", PredictionsOutputKey))
 	err := os.MkdirAll(predictionsDir, os.ModePerm)
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to create predictions output folder")
 	}
 	log.Infof("predictions output folder created ('%s')", predictionsDir)
 
-	commandLine := fmt.Sprintf("python3 runner.py runtime -v %s produce -t %s -f %s -o %s",
-		config.D3MStaticDir, schemaFile, env.ResolvePipelineD3MPath(pipelineID), predictionOutput)
-	cmd := exec.Command("/bin/sh", "-c", commandLine)
+	// remove outputs of previous batches so they do not get read again
+	err = removeOutputs(predictionsDir)
+	if err != nil {
+		return nil, err
+	}
 
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	run, err := runRunner(ctx, pipelineID, "produce", func(pipelineRunPath string) []string {
+		return []string{"runtime", "-v", config.D3MStaticDir, "produce",
+			"-t", schemaFile, "-f", fittedPath, "-o", predictionOutput, "-O", pipelineRunPath}
+	}, config)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("produce output written to '%s'", predictionsDir)
 
-	err = cmd.Run()
-	log.Infof("out: %s", stdout.String())
+	result, err := readOutputs(pipelineID, predictionsDir)
 	if err != nil {
-		log.Errorf("err: %s", stderr.String())
-		return nil, errors.Wrap(err, "unable to run produce command")
+		return nil, err
 	}
-	log.Infof("produce output written to '%s'", predictionOutput)
+	result.RunIDs = []string{run.RunID}
 
-	return util.ReadCSVFile(predictionOutput, true)
+	return result, nil
+}
+
+func removeOutputs(predictionsDir string) error {
+	outputFiles, err := filepath.Glob(path.Join(predictionsDir, "outputs.*.csv"))
+	if err != nil {
+		return errors.Wrap(err, "unable to list previous outputs")
+	}
+	for _, f := range outputFiles {
+		err = os.Remove(f)
+		if err != nil {
+			return errors.Wrapf(err, "unable to remove previous output '%s'", f)
+		}
+	}
+
+	return nil
+}
+
+func readOutputs(pipelineID string, predictionsDir string) (*ProduceResult, error) {
+	outputNames, err := GetPipelineOutputNames(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputFiles, err := filepath.Glob(path.Join(predictionsDir, "outputs.*.csv"))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list produce outputs")
+	}
+
+	result := &ProduceResult{
+		Outputs: make(map[string]*Output),
+	}
+	for _, f := range outputFiles {
+		key := strings.TrimSuffix(path.Base(f), ".csv")
+		data, err := util.ReadCSVFile(f, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			return nil, errors.Errorf("output '%s' is missing its header", key)
+		}
+
+		name := outputNames[key]
+		if name == "" {
+			name = key
+		}
+		result.Outputs[key] = &Output{
+			Key:    key,
+			Name:   name,
+			Header: data[0],
+			Data:   data[1:],
+		}
+	}
+
+	if result.Predictions() == nil {
+		return nil, errors.Errorf("produce did not write the '%s' output", PredictionsOutputKey)
+	}
+
+	return result, nil
 }
 
 // ProduceBatch runs the produce command in batches. Predictions are then returned as they complete.
-func ProduceBatch(pipelineID string, schemaFile string, predictionsID string, queue *Queue, config *env.Config) ([][]string, error) {
+// Every successful batch is checkpointed so a later call for the same rows
+// resumes where it stopped. Failed batches are bisected to isolate the rows
+// causing the failure, which are reported alongside the predictions.
+func ProduceBatch(ctx context.Context, pipelineID string, schemaFile string, predictionsID string, queue *Queue, config *env.Config) (*ProduceResult, error) {
 	log.Infof("producing predictions using batches")
-	batchSize := config.BatchSize
-	rootDatasetPath := env.ResolveDatasetPath(predictionsID)
+	config = config.Reloaded()
+	ctx, span := tracing.Start(ctx, "produce batches", attribute.String("prediction.id", predictionsID))
+	defer span.End()
+
+	// drop whatever is left in the queue if a batch fails
+	defer queue.RemoveDataset(predictionsID)
 
 	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFile, false)
 	if err != nil {
 		return nil, err
 	}
+	d3mIndex := -1
+	for _, v := range meta.GetMainDataResource().Variables {
+		if v.Name == model.D3MIndexName {
+			d3mIndex = v.Index
+		}
+	}
+	if d3mIndex < 0 {
+		return nil, errors.Errorf("d3m index not found in dataset")
+	}
+	media, err := findMediaColumns(meta, schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	columns := meta.GetMainDataResource().GenerateHeader()
+	saved, err := loadCheckpoints(pipelineID, env.ResolvePredictionPath(predictionsID), columns)
+	if err != nil {
+		return nil, err
+	}
 
-	output := make([][]string, 0)
+	p := &batchProducer{
+		pipelineID:    pipelineID,
+		schemaFile:    schemaFile,
+		predictionsID: predictionsID,
+		datasetPath:   env.ResolveDatasetPath(predictionsID),
+		meta:          meta,
+		columns:       columns,
+		d3mIndex:      d3mIndex,
+		media:         media,
+		checkpoints:   saved,
+		config:        config,
+		output: &ProduceResult{
+			Outputs:  make(map[string]*Output),
+			Failures: make([]*RowFailure, 0),
+		},
+	}
+
+	sizer, err := NewBatchSizer(pipelineID, config)
+	if err != nil {
+		return nil, err
+	}
 	count := 1
-	previousThroughput := 10.0
 	for {
-		batch := queue.RemoveEntries(predictionsID, batchSize)
+		batch, err := queue.Take(ctx, predictionsID, sizer.Size())
+		if err != nil {
+			return nil, err
+		}
 		if len(batch) == 0 {
 			break
 		}
 		batchID := fmt.Sprintf("batch-%d", count)
 		log.Infof("pulled %d entries into a batch using id '%s' (%d remaining)", len(batch), batchID, queue.GetLength(predictionsID))
+		count = count + 1
 
-		// write the batch to disk
-		batchPath, err := writeBatch(meta, rootDatasetPath, batchID, batch)
+		// let the next batch in line run once this one is done
+		err = p.produceBatch(ctx, batchID, batch, sizer)
+		queue.Done(predictionsID)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		// remove the leading / from the relative path
-		batchPathRelative := strings.Replace(batchPath, rootDatasetPath, "", 1)[1:]
+	// the next produce call starts from the size reached
+	err = SaveBatchSize(pipelineID, sizer, config)
+	if err != nil {
+		log.Warnf("unable to save batch size of pipeline '%s': %+v", pipelineID, err)
+	}
 
-		// update the metadata
-		mainDR := meta.GetMainDataResource()
-		mainDR.ResPath = batchPathRelative
+	// merge the checkpoints matching the request and produce the rows of
+	// those that only partially matched
+	resumed, resumedRows, unmatched := saved.resume()
+	for _, r := range resumed {
+		p.output.merge(r)
+	}
+	err = JobFromContext(ctx).addProgress(resumedRows, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(unmatched) > 0 {
+		_, err = p.produceRows(ctx, fmt.Sprintf("batch-%d", count), unmatched)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		// write the metadata for the batch
-		err = metadata.WriteSchema(meta, schemaFile, false)
+	if p.output.Predictions() == nil && len(p.output.Failures) > 0 {
+		return nil, util.WithKindDetails(errors.Errorf("produce failed for all %d rows", len(p.output.Failures)),
+			util.KindInternal, p.output.Failures)
+	}
+
+	// keep the checkpoints around only if rows need to be produced again
+	if len(p.output.Failures) == 0 {
+		err = saved.clear()
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	return p.output, nil
+}
+
+// produceBatch produces the rows of a batch taken from the queue, skipping
+// those found in the checkpoints or the prediction cache.
+func (p *batchProducer) produceBatch(ctx context.Context, batchID string, batch [][]string, sizer BatchSizer) error {
+	// rows produced by a previous attempt are not produced again
+	rows := p.checkpoints.skip(batch)
+	if len(rows) < len(batch) {
+		log.Infof("resuming %d rows of batch '%s' from checkpoints", len(batch)-len(rows), batchID)
+	}
 
-		// produce predictions for the batch
-		produceStart := time.Now()
-		batchOutput, err := Produce(pipelineID, schemaFile, predictionsID, config)
+	// neither are rows whose predictions are cached
+	uncached := len(rows)
+	cached, rows := predictionCache.lookup(p.pipelineID, p.columns, p.d3mIndex, p.media, rows)
+	if cached != nil {
+		p.output.merge(cached)
+		err := JobFromContext(ctx).addProgress(uncached-len(rows), 0)
 		if err != nil {
+			return err
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	produceStart := time.Now()
+	complete, err := p.produceRows(ctx, batchID, rows)
+	if err != nil {
+		return err
+	}
+	produceEnd := time.Now()
+
+	sizer.Observe(rows, produceEnd.Sub(produceStart), !complete)
+	return nil
+}
+
+// RowFailure is a row the pipeline failed to produce predictions for.
+type RowFailure struct {
+	ID        string `json:"id"`
+	Error     string `json:"error"`
+	Traceback string `json:"traceback,omitempty"`
+	RunID     string `json:"runId,omitempty"`
+}
+
+// batchProducer produces batches of rows, bisecting the batches that fail.
+type batchProducer struct {
+	pipelineID    string
+	schemaFile    string
+	predictionsID string
+	datasetPath   string
+	meta          *model.Metadata
+	columns       []string
+	d3mIndex      int
+	media         mediaColumns
+	checkpoints   *checkpoints
+	config        *env.Config
+	output        *ProduceResult
+}
+
+// produceRows produces the rows, splitting them in halves on failure until
+// the failing rows are isolated. It returns true if the rows were produced
+// without splitting them.
+func (p *batchProducer) produceRows(ctx context.Context, batchID string, rows [][]string) (bool, error) {
+	attempts := p.config.BatchMaxAttempts
+	failed, err := p.attempt(ctx, batchID, rows, &attempts)
+	if err != nil || failed == nil {
+		return failed == nil, err
+	}
+	return false, p.bisect(ctx, batchID, rows, failed, &attempts)
+}
+
+// attempt runs the pipeline on the rows once, returning the runner error if
+// it failed. Other errors are returned as errors since splitting the rows
+// cannot fix them.
+func (p *batchProducer) attempt(ctx context.Context, batchID string, rows [][]string, attempts *int) (failed error, err error) {
+	metrics.ObserveBatchSize(p.pipelineID, len(rows))
+	*attempts--
+
+	// write the batch to disk and point the dataset to it
+	err = useBatch(ctx, p.meta, p.schemaFile, p.datasetPath, batchID, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// produce predictions for the batch
+	batchOutput, err := Produce(ctx, p.pipelineID, p.schemaFile, p.predictionsID, p.config)
+	if err != nil {
+		if ctx.Err() != nil || runError(err) == nil {
 			return nil, err
 		}
-		produceEnd := time.Now()
+		return err, nil
+	}
 
-		// merge all predictions
-		output = append(output, batchOutput...)
+	p.output.merge(batchOutput)
+	predictionCache.store(p.pipelineID, p.columns, p.d3mIndex, p.media, rows, batchOutput)
+	metrics.AddRowsProcessed("produce", p.pipelineID, len(rows))
+	err = p.checkpoints.save(rows, batchOutput)
+	if err != nil {
+		log.Warnf("unable to checkpoint batch '%s': %+v", batchID, err)
+	}
+	return nil, JobFromContext(ctx).addProgress(len(rows), 0)
+}
 
-		count = count + 1
-		currentTimeTaken := produceEnd.Sub(produceStart)
-		batchSize, previousThroughput = adjustBatchSize(config, float64(batchSize), currentTimeTaken, previousThroughput)
+// bisect isolates the rows causing the failure of the batch by producing
+// each half separately. It stops splitting once the attempts are used up or
+// if the failure does not depend on the rows, which is assumed when both
+// halves and a single row of each fail the same way as the batch.
+func (p *batchProducer) bisect(ctx context.Context, batchID string, rows [][]string, failed error, attempts *int) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if len(rows) == 1 {
+		return p.fail(ctx, rows, failed)
+	}
+	if *attempts <= 0 {
+		log.Warnf("giving up on isolating the failing rows of batch '%s' after %d attempts", batchID, p.config.BatchMaxAttempts)
+		return p.fail(ctx, rows, failed)
+	}
+
+	log.Warnf("batch '%s' of %d rows failed so splitting it: %v", batchID, len(rows), failed)
+	half := len(rows) / 2
+	halves := [][][]string{rows[:half], rows[half:]}
+	failures := make([]error, len(halves))
+	for i, h := range halves {
+		if *attempts <= 0 {
+			failures[i] = failed
+			continue
+		}
+		var err error
+		failures[i], err = p.attempt(ctx, fmt.Sprintf("%s-%d", batchID, i+1), h, attempts)
+		if err != nil {
+			return err
+		}
 	}
 
-	return output, nil
+	if len(rows) > 2 && sameFailure(failed, failures...) {
+		systemic, err := p.isSystemic(ctx, batchID, halves, failed, attempts)
+		if err != nil {
+			return err
+		}
+		if systemic {
+			log.Warnf("every part of batch '%s' fails the same way so not splitting it further", batchID)
+			return p.fail(ctx, rows, failed)
+		}
+	}
+
+	for i, h := range halves {
+		if failures[i] == nil {
+			continue
+		}
+		err := p.bisect(ctx, fmt.Sprintf("%s-%d", batchID, i+1), h, failures[i], attempts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isSystemic produces the first row of each half on its own, returning true
+// if they also fail the same way as the batch. Rows that succeed are not
+// produced again.
+func (p *batchProducer) isSystemic(ctx context.Context, batchID string, halves [][][]string, failed error, attempts *int) (bool, error) {
+	systemic := true
+	for i, h := range halves {
+		if *attempts <= 0 {
+			return false, nil
+		}
+		probe, err := p.attempt(ctx, fmt.Sprintf("%s-%d-probe", batchID, i+1), h[:1], attempts)
+		if err != nil {
+			return false, err
+		}
+		if probe == nil {
+			halves[i] = h[1:]
+		}
+		systemic = systemic && sameFailure(failed, probe)
+	}
+	return systemic, nil
 }
 
-func writeBatch(meta *model.Metadata, datasetPath string, batchID string, data [][]string) (string, error) {
+// fail reports every row as failed with the error.
+func (p *batchProducer) fail(ctx context.Context, rows [][]string, err error) error {
+	for _, row := range rows {
+		failure := &RowFailure{
+			ID:    row[p.d3mIndex],
+			Error: err.Error(),
+		}
+		if runErr := runError(err); runErr != nil {
+			failure.RunID = runErr.RunID
+			failure.Traceback = runErr.Traceback
+		}
+		log.Warnf("produce failed for row '%s': %s", failure.ID, failure.Error)
+		p.output.Failures = append(p.output.Failures, failure)
+	}
+	err = JobFromContext(ctx).addProgress(len(rows), len(rows))
+	if err != nil {
+		return err
+	}
+
+	if len(p.output.Failures) > p.config.BatchMaxFailures {
+		return util.WithKindDetails(errors.Errorf("produce failed for more than %d rows", p.config.BatchMaxFailures),
+			util.KindInternal, p.output.Failures)
+	}
+
+	return nil
+}
+
+// runError returns the details of a failed runner invocation, or nil if the
+// error did not come from the runner.
+func runError(err error) *RunError {
+	if _, details := util.GetErrorKind(err); details != nil {
+		if runErr, ok := details.(*RunError); ok {
+			return runErr
+		}
+	}
+	return nil
+}
+
+// sameFailure returns true if every error is a runner failure with the same
+// traceback as the first.
+func sameFailure(failed error, others ...error) bool {
+	expected := runError(failed)
+	for _, other := range others {
+		runErr := runError(other)
+		if runErr == nil || runErr.Traceback != expected.Traceback {
+			return false
+		}
+	}
+	return true
+}
+
+// useBatch writes the batch data to disk and updates the dataset schema so
+// its main data resource points to the batch.
+func useBatch(ctx context.Context, meta *model.Metadata, schemaFile string, datasetPath string, batchID string, data [][]string) error {
+	batchPath, err := writeBatch(ctx, meta, datasetPath, batchID, data)
+	if err != nil {
+		return err
+	}
+
+	// remove the leading / from the relative path
+	batchPathRelative := strings.Replace(batchPath, datasetPath, "", 1)[1:]
+
+	// update the metadata
+	mainDR := meta.GetMainDataResource()
+	mainDR.ResPath = batchPathRelative
+
+	// write the metadata for the batch
+	return metadata.WriteSchema(meta, schemaFile, false)
+}
+
+func writeBatch(ctx context.Context, meta *model.Metadata, datasetPath string, batchID string, data [][]string) (string, error) {
+	_, span := tracing.Start(ctx, "write batch", attribute.String("batch.id", batchID), attribute.Int("rows", len(data)))
+	defer span.End()
+
 	// get batch data folder
 	batchOutputPath := path.Join(datasetPath, batchID, compute.D3MLearningData)
 	log.Infof("storing batch to '%s'", batchOutputPath)
@@ -152,18 +614,3 @@ func writeBatch(meta *model.Metadata, datasetPath string, batchID string, data [
 
 	return batchOutputPath, nil
 }
-
-func adjustBatchSize(config *env.Config, currentBatchSize float64, currentTimeTaken time.Duration, previousThroughput float64) (int, float64) {
-	currentThroughput := currentBatchSize / currentTimeTaken.Seconds()
-	if currentThroughput > previousThroughput {
-		newSize := int(currentBatchSize * config.BatchSizeIncreaseFactor)
-		log.Infof("latest batch had higher throughput (%v) than previous batch (%v) so increasing batch size to %d",
-			currentThroughput, previousThroughput, newSize)
-		return newSize, currentThroughput
-	}
-
-	newSize := int(currentBatchSize * config.BatchSizeDecreaseFactor)
-	log.Infof("latest batch had lower throughput (%v) than previous batch (%v) so decreasing batch size to %d",
-		currentThroughput, previousThroughput, newSize)
-	return newSize, currentThroughput
-}
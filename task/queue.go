@@ -15,31 +15,146 @@
 
 package task
 
-// Queue queues rows from datasets.
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// Queue queues rows from datasets and schedules the batches pulled from
+// them. It is safe for concurrent use by multiple producers and consumers.
+// At most workers batches are handed out at once, and consumers waiting for
+// a batch are served in turn, those of datasets with a higher priority
+// first.
 type Queue struct {
-	datasets map[string][][]string
+	mutex    sync.Mutex
+	changed  chan struct{}
+	datasets map[string]*datasetQueue
+	ring     []*datasetQueue
+	position int
+	workers  int
+	active   int
 }
 
-// NewQueue creates a new queue.
-func NewQueue() *Queue {
+// datasetQueue holds the rows of a dataset along with the count of its
+// consumers waiting for a batch, running one and coming back for another
+// after running one.
+type datasetQueue struct {
+	id        string
+	priority  int
+	rows      [][]string
+	closed    bool
+	waiting   int
+	active    int
+	returning int
+}
+
+// NewQueue creates a new queue handing out at most workers batches at once,
+// or any number of batches if workers is not positive.
+func NewQueue(workers int) *Queue {
 	return &Queue{
-		datasets: make(map[string][][]string),
+		changed:  make(chan struct{}),
+		datasets: make(map[string]*datasetQueue),
+		workers:  workers,
 	}
 }
 
-// AddDataset adds a dataset to the queue.
-func (q *Queue) AddDataset(dataset string) {
-	q.datasets[dataset] = make([][]string, 0)
+// AddDataset adds a dataset to the queue with the specified priority.
+func (q *Queue) AddDataset(dataset string, priority int) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, ok := q.datasets[dataset]; ok {
+		return util.WithKind(errors.Errorf("dataset '%s' is already queued", dataset), util.KindConflict)
+	}
+	d := &datasetQueue{
+		id:       dataset,
+		priority: priority,
+		rows:     make([][]string, 0),
+	}
+	q.datasets[dataset] = d
+	q.ring = append(q.ring, d)
+	metrics.AddQueueDatasets(1)
+
+	return nil
+}
+
+// CloseDataset flags that no more rows will be added to the dataset so
+// consumers waiting on it stop once it is drained.
+func (q *Queue) CloseDataset(dataset string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if d, ok := q.datasets[dataset]; ok {
+		d.closed = true
+		q.notify()
+	}
 }
 
 // GetLength returns the count of entries in the queue for the specified dataset.
 func (q *Queue) GetLength(dataset string) int {
-	return len(q.datasets[dataset])
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	d, ok := q.datasets[dataset]
+	if !ok {
+		return 0
+	}
+	return len(d.rows)
 }
 
 // AddEntry adds a row to the dataset queue.
-func (q *Queue) AddEntry(dataset string, row []string) {
-	q.datasets[dataset] = append(q.datasets[dataset], row)
+func (q *Queue) AddEntry(dataset string, row []string) error {
+	return q.AddEntries(dataset, [][]string{row})
+}
+
+// AddEntries adds rows to the dataset queue.
+func (q *Queue) AddEntries(dataset string, rows [][]string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	d, ok := q.datasets[dataset]
+	if !ok {
+		return errors.Errorf("dataset '%s' is not queued", dataset)
+	}
+	if d.closed {
+		return errors.Errorf("dataset '%s' is closed", dataset)
+	}
+	d.rows = append(d.rows, rows...)
+	metrics.AddQueueDepth(len(rows))
+	q.notify()
+
+	return nil
+}
+
+// RemoveDataset removes the dataset and any rows left in its queue.
+func (q *Queue) RemoveDataset(dataset string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	d, ok := q.datasets[dataset]
+	if !ok {
+		return
+	}
+	metrics.AddQueueDepth(-len(d.rows))
+	metrics.AddQueueDatasets(-1)
+	delete(q.datasets, dataset)
+	q.active = q.active - d.active
+	metrics.AddQueueActive(-d.active)
+	for i, r := range q.ring {
+		if r == d {
+			q.ring = append(q.ring[:i], q.ring[i+1:]...)
+			if q.position > i {
+				q.position--
+			}
+			break
+		}
+	}
+	q.notify()
 }
 
 // RemoveEntry removes the first row from the dataset queue.
@@ -48,21 +163,129 @@ func (q *Queue) RemoveEntry(dataset string) []string {
 	if len(entries) < 1 {
 		return nil
 	}
-	return entries[1]
+	return entries[0]
 }
 
 // RemoveEntries removes the first n rows from the dataset queue.
 func (q *Queue) RemoveEntries(dataset string, count int) [][]string {
-	datasetData := q.datasets[dataset]
-	if len(datasetData) == 0 {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	d, ok := q.datasets[dataset]
+	if !ok {
+		return nil
+	}
+	return d.take(count)
+}
+
+// Take removes up to count rows from the dataset queue once it is the turn
+// of the dataset to run a batch, waiting for rows to be added if it is empty.
+// Every batch taken must be reported as done to let other batches run. It
+// returns no rows once the dataset is closed and drained or removed from the
+// queue.
+func (q *Queue) Take(ctx context.Context, dataset string, count int) ([][]string, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	d, ok := q.datasets[dataset]
+	if !ok {
+		return nil, nil
+	}
+	if d.returning > 0 {
+		d.returning--
+	}
+	d.waiting++
+	defer func() {
+		d.waiting--
+	}()
+
+	for {
+		if _, ok := q.datasets[dataset]; !ok {
+			return nil, nil
+		}
+		if len(d.rows) == 0 && d.closed {
+			return nil, nil
+		}
+		if picked, index := q.pick(); picked == d {
+			q.position = index + 1
+			rows := d.take(count)
+			d.active++
+			q.active++
+			metrics.AddQueueActive(1)
+			// someone else may be next in line
+			q.notify()
+			return rows, nil
+		}
+		changed := q.changed
+		q.mutex.Unlock()
+
+		select {
+		case <-changed:
+			q.mutex.Lock()
+		case <-ctx.Done():
+			q.mutex.Lock()
+			// the dataset may have been the next in line
+			q.notify()
+			return nil, errors.Wrapf(ctx.Err(), "stopped waiting for rows of dataset '%s'", dataset)
+		}
+	}
+}
+
+// Done flags a batch taken from the dataset as complete.
+func (q *Queue) Done(dataset string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	d, ok := q.datasets[dataset]
+	if !ok || d.active == 0 {
+		return
+	}
+	d.active--
+	d.returning++
+	q.active--
+	metrics.AddQueueActive(-1)
+	q.notify()
+}
+
+// pick returns the dataset whose consumer gets the next batch, and its
+// position in the ring, or nil if no batch can be handed out. Consumers that
+// just completed a batch keep their place so a dataset does not lose its
+// priority between batches. The search starts after the last dataset served
+// so datasets of equal priority take turns. The mutex must be held.
+func (q *Queue) pick() (*datasetQueue, int) {
+	if q.workers > 0 && q.active >= q.workers {
+		return nil, 0
+	}
+
+	var picked *datasetQueue
+	pickedIndex := 0
+	for i := 0; i < len(q.ring); i++ {
+		index := (q.position + i) % len(q.ring)
+		d := q.ring[index]
+		if d.waiting+d.returning > 0 && len(d.rows) > 0 && (picked == nil || d.priority > picked.priority) {
+			picked = d
+			pickedIndex = index
+		}
+	}
+	return picked, pickedIndex
+}
+
+// notify wakes up all consumers waiting for changes. The mutex must be held.
+func (q *Queue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+func (d *datasetQueue) take(count int) [][]string {
+	if len(d.rows) == 0 {
 		return nil
-	} else if len(datasetData) < count {
-		count = len(datasetData)
+	} else if len(d.rows) < count {
+		count = len(d.rows)
 	}
 
-	entries := datasetData[:count]
-	datasetData = datasetData[count:]
-	q.datasets[dataset] = datasetData
+	entries := d.rows[:count]
+	d.rows = d.rows[count:]
+	metrics.AddQueueDepth(-count)
 
 	return entries
 }
@@ -0,0 +1,288 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor polls the condition, failing the test if it does not hold in time.
+func waitFor(t *testing.T, q *Queue, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		q.mutex.Lock()
+		ok := condition()
+		q.mutex.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("condition not met in time")
+}
+
+func addDataset(t *testing.T, q *Queue, dataset string, priority int, count int) {
+	err := q.AddDataset(dataset, priority)
+	if err != nil {
+		t.Fatalf("unable to add dataset: %v", err)
+	}
+	for i := 0; i < count; i++ {
+		err = q.AddEntry(dataset, []string{fmt.Sprintf("%s-%d", dataset, i)})
+		if err != nil {
+			t.Fatalf("unable to add entry: %v", err)
+		}
+	}
+	q.CloseDataset(dataset)
+}
+
+// consume takes single rows from the dataset until it is drained, recording
+// the dataset of every batch in the order they were taken. It pauses between
+// batches like a producer handling the results of a batch would.
+func consume(q *Queue, dataset string, order *[]string, mutex *sync.Mutex, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		rows, err := q.Take(context.Background(), dataset, 1)
+		if err != nil || len(rows) == 0 {
+			return
+		}
+		mutex.Lock()
+		*order = append(*order, dataset)
+		mutex.Unlock()
+		q.Done(dataset)
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// holdSlot takes a batch from a dataset of its own so other consumers queue
+// up behind it until the returned function is called.
+func holdSlot(t *testing.T, q *Queue) func() {
+	addDataset(t, q, "blocker", 100, 1)
+	rows, err := q.Take(context.Background(), "blocker", 1)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("unable to take the blocking batch: %v", err)
+	}
+	return func() {
+		q.Done("blocker")
+	}
+}
+
+func TestQueueProducersConsumers(t *testing.T) {
+	const (
+		workers   = 3
+		datasets  = 4
+		producers = 3
+		consumers = 2
+		rows      = 200
+	)
+	q := NewQueue(workers)
+
+	var active, maxActive int32
+	var seen sync.Map
+	var taken int32
+	var wg sync.WaitGroup
+	for d := 0; d < datasets; d++ {
+		dataset := fmt.Sprintf("dataset-%d", d)
+		err := q.AddDataset(dataset, d%2)
+		if err != nil {
+			t.Fatalf("unable to add dataset: %v", err)
+		}
+
+		var produced sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			produced.Add(1)
+			go func(p int) {
+				defer produced.Done()
+				for i := 0; i < rows; i++ {
+					err := q.AddEntry(dataset, []string{fmt.Sprintf("%s-%d-%d", dataset, p, i)})
+					if err != nil {
+						t.Errorf("unable to add entry: %v", err)
+						return
+					}
+				}
+			}(p)
+		}
+		go func() {
+			produced.Wait()
+			q.CloseDataset(dataset)
+		}()
+
+		for c := 0; c < consumers; c++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					batch, err := q.Take(context.Background(), dataset, 7)
+					if err != nil {
+						t.Errorf("unable to take rows: %v", err)
+						return
+					}
+					if len(batch) == 0 {
+						return
+					}
+					current := atomic.AddInt32(&active, 1)
+					for {
+						max := atomic.LoadInt32(&maxActive)
+						if current <= max || atomic.CompareAndSwapInt32(&maxActive, max, current) {
+							break
+						}
+					}
+					for _, row := range batch {
+						if _, loaded := seen.LoadOrStore(row[0], true); loaded {
+							t.Errorf("row '%s' taken twice", row[0])
+						}
+						atomic.AddInt32(&taken, 1)
+					}
+					atomic.AddInt32(&active, -1)
+					q.Done(dataset)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if expected := int32(datasets * producers * rows); taken != expected {
+		t.Errorf("expected %d rows to be taken, got %d", expected, taken)
+	}
+	if maxActive > workers {
+		t.Errorf("expected at most %d batches at once, got %d", workers, maxActive)
+	}
+	if q.GetLength("dataset-0") != 0 {
+		t.Errorf("expected the queue to be drained")
+	}
+}
+
+func TestQueuePriority(t *testing.T) {
+	q := NewQueue(1)
+	release := holdSlot(t, q)
+	addDataset(t, q, "low", 0, 3)
+	addDataset(t, q, "high", 1, 3)
+
+	order := make([]string, 0)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go consume(q, "low", &order, &mutex, &wg)
+	go consume(q, "high", &order, &mutex, &wg)
+	waitFor(t, q, func() bool {
+		return q.datasets["low"].waiting == 1 && q.datasets["high"].waiting == 1
+	})
+	release()
+	wg.Wait()
+
+	expected := []string{"high", "high", "high", "low", "low", "low"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("expected batches in order %v, got %v", expected, order)
+	}
+}
+
+func TestQueueRoundRobin(t *testing.T) {
+	q := NewQueue(1)
+	release := holdSlot(t, q)
+	addDataset(t, q, "a", 0, 3)
+	addDataset(t, q, "b", 0, 3)
+
+	order := make([]string, 0)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go consume(q, "a", &order, &mutex, &wg)
+	go consume(q, "b", &order, &mutex, &wg)
+	waitFor(t, q, func() bool {
+		return q.datasets["a"].waiting == 1 && q.datasets["b"].waiting == 1
+	})
+	release()
+	wg.Wait()
+
+	for i := 1; i < len(order); i++ {
+		if order[i] == order[i-1] {
+			t.Fatalf("expected datasets to take turns, got %v", order)
+		}
+	}
+	if len(order) != 6 {
+		t.Errorf("expected 6 batches, got %v", order)
+	}
+}
+
+func TestQueueTakeCancelled(t *testing.T) {
+	q := NewQueue(1)
+
+	// waiting on an empty dataset
+	err := q.AddDataset("empty", 0)
+	if err != nil {
+		t.Fatalf("unable to add dataset: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	rows, err := q.Take(ctx, "empty", 1)
+	if err == nil || rows != nil {
+		t.Errorf("expected waiting on an empty dataset to be cancelled, got %v", rows)
+	}
+
+	// waiting for a slot held by another batch
+	release := holdSlot(t, q)
+	addDataset(t, q, "queued", 0, 1)
+	ctx, cancel = context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := q.Take(ctx, "queued", 1)
+		done <- err
+	}()
+	waitFor(t, q, func() bool {
+		return q.datasets["queued"].waiting == 1
+	})
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected waiting for a slot to be cancelled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("take did not return once cancelled")
+	}
+
+	// the rows are still there for the next consumer
+	release()
+	rows, err = q.Take(context.Background(), "queued", 1)
+	if err != nil || len(rows) != 1 {
+		t.Errorf("expected the queued row once the slot is free, got %v, %v", rows, err)
+	}
+	q.Done("queued")
+
+	// removing a dataset stops its consumers
+	err = q.AddDataset("removed", 0)
+	if err != nil {
+		t.Fatalf("unable to add dataset: %v", err)
+	}
+	go func() {
+		rows, err := q.Take(context.Background(), "removed", 1)
+		if err != nil || rows != nil {
+			err = fmt.Errorf("expected no rows, got %v, %v", rows, err)
+		}
+		done <- err
+	}()
+	waitFor(t, q, func() bool {
+		return q.datasets["removed"].waiting == 1
+	})
+	q.RemoveDataset("removed")
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,298 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/metrics"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// RunInfoFile is the name of the file describing a run.
+	RunInfoFile = "run.json"
+	// RunStdoutFile is the name of the file holding the runner stdout.
+	RunStdoutFile = "stdout.log"
+	// RunStderrFile is the name of the file holding the runner stderr.
+	RunStderrFile = "stderr.log"
+	// RunPipelineRunFile is the name of the D3M pipeline run document.
+	RunPipelineRunFile = "pipeline_run.yaml"
+)
+
+// RunInfo describes a single invocation of the runner.
+type RunInfo struct {
+	RunID       string    `json:"runId"`
+	PipelineID  string    `json:"pipelineId"`
+	Command     string    `json:"command"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	Success     bool      `json:"success"`
+	Error       string    `json:"error,omitempty"`
+	Traceback   string    `json:"traceback,omitempty"`
+	PipelineRun bool      `json:"pipelineRun"`
+}
+
+// RunError details a failed runner invocation.
+type RunError struct {
+	RunID     string `json:"runId"`
+	Traceback string `json:"traceback,omitempty"`
+}
+
+// runRunner executes the runner script with the arguments built for the run,
+// persisting its output and the pipeline run document it produces in the run
+// folder. The arguments should make the runner write the pipeline run
+// document to the path it receives.
+func runRunner(ctx context.Context, pipelineID string, command string, buildArgs func(pipelineRunPath string) []string, config *env.Config) (*RunInfo, error) {
+	run := &RunInfo{
+		RunID:      newRunID(command),
+		PipelineID: pipelineID,
+		Command:    command,
+	}
+	runPath := env.ResolveRunPath(pipelineID, run.RunID)
+	err := os.MkdirAll(runPath, os.ModePerm)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create run folder")
+	}
+
+	pipelineRunPath := path.Join(runPath, RunPipelineRunFile)
+	args := append([]string{config.RunnerScript}, buildArgs(pipelineRunPath)...)
+	cmd := exec.Command(config.RunnerPython, args...)
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// pass the trace context to the runner so its spans are linked
+	runCtx, span := tracing.Start(ctx, "runner "+command,
+		attribute.String("pipeline.id", pipelineID), attribute.String("run.id", run.RunID))
+	cmd.Env = append(runnerEnvironment(config), tracing.Environment(runCtx)...)
+	log.Infof("running %s %s", config.RunnerPython, strings.Join(args, " "))
+
+	run.StartTime = time.Now()
+	err = cmd.Run()
+	run.EndTime = time.Now()
+	tracing.End(span, err)
+	metrics.ObserveRunner(command, pipelineID, run.EndTime.Sub(run.StartTime), err != nil)
+	log.Infof("out: %s", stdout.String())
+
+	run.Success = err == nil
+	run.PipelineRun = util.FileExists(pipelineRunPath)
+	if err != nil {
+		log.Errorf("err: %s", stderr.String())
+		run.Error = err.Error()
+		run.Traceback = summarizeTraceback(stderr.String())
+	}
+
+	// keep the output around for troubleshooting
+	persistErr := persistRun(runPath, run, stdout.Bytes(), stderr.Bytes())
+	if persistErr != nil {
+		log.Warnf("unable to persist run '%s': %+v", run.RunID, persistErr)
+	}
+	jobErr := JobFromContext(ctx).addRun(run.RunID)
+	if jobErr != nil {
+		log.Warnf("unable to record run '%s' with its job: %+v", run.RunID, jobErr)
+	}
+
+	if err != nil {
+		return run, util.WithKindDetails(errors.Wrapf(err, "unable to run %s command", command), util.KindInternal, &RunError{
+			RunID:     run.RunID,
+			Traceback: run.Traceback,
+		})
+	}
+
+	return run, nil
+}
+
+// runnerEnvironment returns the variables of the service environment the
+// runner is allowed to see. Everything else, credentials included, is withheld.
+func runnerEnvironment(config *env.Config) []string {
+	environment := []string{"PYTHONUNBUFFERED=1"}
+	for _, name := range config.RunnerEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			environment = append(environment, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	return environment
+}
+
+func persistRun(runPath string, run *RunInfo, stdout []byte, stderr []byte) error {
+	err := ioutil.WriteFile(path.Join(runPath, RunStdoutFile), stdout, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "unable to write stdout")
+	}
+	err = ioutil.WriteFile(path.Join(runPath, RunStderrFile), stderr, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "unable to write stderr")
+	}
+
+	runJSON, err := json.Marshal(run)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal run info")
+	}
+	err = ioutil.WriteFile(path.Join(runPath, RunInfoFile), runJSON, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "unable to write run info")
+	}
+
+	return nil
+}
+
+// GetRuns returns the runs of the pipeline, most recent first.
+func GetRuns(pipelineID string) ([]*RunInfo, error) {
+	runs := make([]*RunInfo, 0)
+	runsPath := env.ResolveRunsPath(pipelineID)
+	if !util.FileExists(runsPath) {
+		return runs, nil
+	}
+
+	directories, err := util.GetDirectories(runsPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range directories {
+		run, err := GetRun(pipelineID, path.Base(d))
+		if err != nil {
+			log.Warnf("skipping run found in '%s': %v", d, err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartTime.After(runs[j].StartTime)
+	})
+
+	return runs, nil
+}
+
+// PruneRuns removes the run folders of every pipeline that were last
+// modified before the retention, returning the number of runs removed.
+// Pipeline folders left without runs are removed as well.
+func PruneRuns(directory string, retention time.Duration) (int, error) {
+	if !util.FileExists(directory) {
+		return 0, nil
+	}
+	pipelines, err := util.GetDirectories(directory)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-retention)
+	removed := 0
+	for _, p := range pipelines {
+		runs, err := util.GetDirectories(p)
+		if err != nil {
+			return removed, err
+		}
+		kept := len(runs)
+		for _, r := range runs {
+			modTime, err := util.GetLastModifiedTime(r)
+			if err != nil {
+				return removed, err
+			}
+			if modTime.After(cutoff) {
+				continue
+			}
+			err = os.RemoveAll(r)
+			if err != nil {
+				return removed, errors.Wrapf(err, "unable to remove run '%s'", path.Base(r))
+			}
+			removed++
+			kept--
+		}
+		if kept == 0 {
+			// ignore the failure if a run was started in the meantime
+			os.Remove(p)
+		}
+	}
+
+	return removed, nil
+}
+
+// GetRun returns the description of a single run.
+func GetRun(pipelineID string, runID string) (*RunInfo, error) {
+	data, err := ioutil.ReadFile(path.Join(env.ResolveRunPath(pipelineID, runID), RunInfoFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, util.NewNotFoundError("run '%s' not found for pipeline '%s'", runID, pipelineID)
+		}
+		return nil, errors.Wrapf(err, "unable to read run '%s'", runID)
+	}
+
+	run := &RunInfo{}
+	err = json.Unmarshal(data, run)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse run '%s'", runID)
+	}
+
+	return run, nil
+}
+
+// summarizeTraceback extracts the last python traceback found in the output,
+// keeping the innermost frame and the exception raised.
+func summarizeTraceback(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	start := -1
+	for i, l := range lines {
+		if strings.HasPrefix(l, "Traceback (most recent call last)") {
+			start = i
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+
+	// frames are indented, the exception is the first unindented line after them
+	lastFrame := ""
+	for _, l := range lines[start+1:] {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "File ") {
+			lastFrame = trimmed
+		} else if trimmed != "" && !strings.HasPrefix(l, " ") {
+			if lastFrame == "" {
+				return trimmed
+			}
+			return fmt.Sprintf("%s\n%s", lastFrame, trimmed)
+		}
+	}
+
+	return lastFrame
+}
+
+func newRunID(command string) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%s-%s", command, time.Now().UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,69 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+func TestPruneRuns(t *testing.T) {
+	defer util.RemoveContents(testConfig.RunDir)
+
+	old := time.Now().Add(-2 * time.Hour)
+	runs := []struct {
+		pipelineID string
+		runID      string
+		old        bool
+	}{
+		{"kept", "fit-old", true},
+		{"kept", "produce-new", false},
+		{"emptied", "fit-old", true},
+	}
+	for _, r := range runs {
+		runPath := env.ResolveRunPath(r.pipelineID, r.runID)
+		err := os.MkdirAll(runPath, os.ModePerm)
+		if err != nil {
+			t.Fatalf("unable to create run: %v", err)
+		}
+		if r.old {
+			err = os.Chtimes(runPath, old, old)
+			if err != nil {
+				t.Fatalf("unable to age run: %v", err)
+			}
+		}
+	}
+
+	removed, err := PruneRuns(testConfig.RunDir, time.Hour)
+	if err != nil {
+		t.Fatalf("unable to prune runs: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 runs to be removed, got %d", removed)
+	}
+	for _, r := range runs {
+		if util.FileExists(env.ResolveRunPath(r.pipelineID, r.runID)) == r.old {
+			t.Errorf("expected run '%s' of '%s' to be removed: %v", r.runID, r.pipelineID, r.old)
+		}
+	}
+	if util.FileExists(env.ResolveRunsPath("emptied")) {
+		t.Errorf("expected the pipeline without runs to be removed")
+	}
+}
@@ -0,0 +1,303 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+// Score is the value of a single performance metric.
+type Score struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+}
+
+// ConfusionMatrix counts the predicted labels (columns) for each true label
+// (rows).
+type ConfusionMatrix struct {
+	Labels []string `json:"labels"`
+	Counts [][]int  `json:"counts"`
+}
+
+// ScoreResult is the outcome of evaluating predictions against labels.
+type ScoreResult struct {
+	Scores          []*Score         `json:"scores"`
+	ConfusionMatrix *ConfusionMatrix `json:"confusionMatrix,omitempty"`
+	Count           int              `json:"count"`
+	Unmatched       int              `json:"unmatched"`
+}
+
+// GetScore returns the score for the specified metric, or nil if it was
+// not computed.
+func (s *ScoreResult) GetScore(metric string) *Score {
+	for _, sc := range s.Scores {
+		if sc.Metric == metric {
+			return sc
+		}
+	}
+	return nil
+}
+
+// HigherIsBetter returns true if larger values of the metric indicate a
+// better model.
+func HigherIsBetter(metric string) bool {
+	switch metric {
+	case "meanSquaredError", "rootMeanSquaredError", "meanAbsoluteError", "hammingLoss":
+		return false
+	default:
+		return true
+	}
+}
+
+// ScorePredictions evaluates the predicted values against the true values,
+// both keyed by d3m index, using the metrics of the problem. Only rows found
+// in both sets are scored.
+func ScorePredictions(problem *Problem, truth map[string]string, predicted map[string]string) (*ScoreResult, error) {
+	ids := make([]string, 0)
+	for id := range truth {
+		if _, ok := predicted[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, util.NewInvalidError("no predictions match the labelled data")
+	}
+	sort.Strings(ids)
+
+	labels := make([]string, len(ids))
+	predictions := make([]string, len(ids))
+	for i, id := range ids {
+		labels[i] = truth[id]
+		predictions[i] = predicted[id]
+	}
+
+	result := &ScoreResult{
+		Scores:    make([]*Score, 0),
+		Count:     len(ids),
+		Unmatched: len(truth) - len(ids),
+	}
+	for _, m := range problem.GetMetrics() {
+		value, err := computeMetric(m, labels, predictions)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			log.Warnf("metric '%s' is not supported and will not be scored", m.Metric)
+			continue
+		}
+		result.Scores = append(result.Scores, &Score{
+			Metric: m.Metric,
+			Value:  *value,
+		})
+	}
+
+	if problem.IsClassification() {
+		result.ConfusionMatrix = buildConfusionMatrix(labels, predictions)
+	}
+
+	return result, nil
+}
+
+// computeMetric computes a single metric, returning nil for unsupported
+// metrics.
+func computeMetric(metric *ProblemMetric, labels []string, predictions []string) (*float64, error) {
+	var value float64
+	switch metric.Metric {
+	case "accuracy", "f1Micro":
+		// micro averaged f1 is the accuracy for single label problems
+		value = accuracy(labels, predictions)
+	case "precision", "recall", "f1":
+		posLabel := metric.getParam("posLabel")
+		if posLabel == "" {
+			posLabel = "1"
+		}
+		precision, recall := precisionRecall(labels, predictions, posLabel)
+		switch metric.Metric {
+		case "precision":
+			value = precision
+		case "recall":
+			value = recall
+		default:
+			value = f1(precision, recall)
+		}
+	case "f1Macro":
+		value = f1Macro(labels, predictions)
+	case "meanSquaredError", "rootMeanSquaredError", "meanAbsoluteError", "rSquared":
+		truth, err := parseFloats(labels)
+		if err != nil {
+			return nil, util.WithKind(errors.Wrapf(err, "unable to parse labels for metric '%s'", metric.Metric), util.KindInvalid)
+		}
+		predicted, err := parseFloats(predictions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse predictions for metric '%s'", metric.Metric)
+		}
+		switch metric.Metric {
+		case "meanSquaredError":
+			value = meanSquaredError(truth, predicted)
+		case "rootMeanSquaredError":
+			value = math.Sqrt(meanSquaredError(truth, predicted))
+		case "meanAbsoluteError":
+			value = meanAbsoluteError(truth, predicted)
+		default:
+			value = rSquared(truth, predicted)
+		}
+	default:
+		return nil, nil
+	}
+
+	return &value, nil
+}
+
+func accuracy(labels []string, predictions []string) float64 {
+	correct := 0
+	for i, l := range labels {
+		if l == predictions[i] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(labels))
+}
+
+func precisionRecall(labels []string, predictions []string, posLabel string) (float64, float64) {
+	truePositives := 0
+	predictedPositives := 0
+	actualPositives := 0
+	for i, l := range labels {
+		if predictions[i] == posLabel {
+			predictedPositives++
+			if l == posLabel {
+				truePositives++
+			}
+		}
+		if l == posLabel {
+			actualPositives++
+		}
+	}
+
+	precision := 0.0
+	if predictedPositives > 0 {
+		precision = float64(truePositives) / float64(predictedPositives)
+	}
+	recall := 0.0
+	if actualPositives > 0 {
+		recall = float64(truePositives) / float64(actualPositives)
+	}
+	return precision, recall
+}
+
+func f1(precision float64, recall float64) float64 {
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
+
+func f1Macro(labels []string, predictions []string) float64 {
+	classes := getClasses(labels, predictions)
+	total := 0.0
+	for _, c := range classes {
+		total = total + f1(precisionRecall(labels, predictions, c))
+	}
+	return total / float64(len(classes))
+}
+
+func meanSquaredError(truth []float64, predicted []float64) float64 {
+	total := 0.0
+	for i, t := range truth {
+		total = total + (t-predicted[i])*(t-predicted[i])
+	}
+	return total / float64(len(truth))
+}
+
+func meanAbsoluteError(truth []float64, predicted []float64) float64 {
+	total := 0.0
+	for i, t := range truth {
+		total = total + math.Abs(t-predicted[i])
+	}
+	return total / float64(len(truth))
+}
+
+func rSquared(truth []float64, predicted []float64) float64 {
+	mean := 0.0
+	for _, t := range truth {
+		mean = mean + t
+	}
+	mean = mean / float64(len(truth))
+
+	residual := 0.0
+	variance := 0.0
+	for i, t := range truth {
+		residual = residual + (t-predicted[i])*(t-predicted[i])
+		variance = variance + (t-mean)*(t-mean)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return 1 - residual/variance
+}
+
+func buildConfusionMatrix(labels []string, predictions []string) *ConfusionMatrix {
+	classes := getClasses(labels, predictions)
+	classIndices := make(map[string]int)
+	counts := make([][]int, len(classes))
+	for i, c := range classes {
+		classIndices[c] = i
+		counts[i] = make([]int, len(classes))
+	}
+
+	for i, l := range labels {
+		counts[classIndices[l]][classIndices[predictions[i]]]++
+	}
+
+	return &ConfusionMatrix{
+		Labels: classes,
+		Counts: counts,
+	}
+}
+
+// getClasses returns the sorted set of labels found in either list.
+func getClasses(labels []string, predictions []string) []string {
+	classSet := make(map[string]bool)
+	for i, l := range labels {
+		classSet[l] = true
+		classSet[predictions[i]] = true
+	}
+	classes := make([]string, 0, len(classSet))
+	for c := range classSet {
+		classes = append(classes, c)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+func parseFloats(values []string) ([]float64, error) {
+	parsed := make([]float64, len(values))
+	for i, v := range values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse '%s' as a number", v)
+		}
+		parsed[i] = f
+	}
+	return parsed, nil
+}
@@ -0,0 +1,210 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-compute/metadata"
+	cm "github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// TrainingNone fits on the received data only.
+	TrainingNone = ""
+	// TrainingAppend fits on the received data merged into the training store.
+	TrainingAppend = "append"
+	// TrainingReplace fits on the received data after emptying the training
+	// store.
+	TrainingReplace = "replace"
+)
+
+var (
+	trainingLocks = &sync.Map{}
+)
+
+// CheckTrainingMode makes sure the training data mode is supported.
+func CheckTrainingMode(mode string) error {
+	if mode != TrainingNone && mode != TrainingAppend && mode != TrainingReplace {
+		return util.NewInvalidError("unsupported training data mode '%s'", mode)
+	}
+	return nil
+}
+
+// AccumulateTrainingData merges the labelled rows of the dataset into the
+// training store of the pipeline, then rewrites the dataset to hold every
+// accumulated row. Rows are deduplicated by d3m index with newer rows
+// replacing older ones. Stored rows are realigned by column name when the
+// dataset columns changed. If replace is set, the store is emptied first.
+// The number of accumulated rows is returned.
+func AccumulateTrainingData(ctx context.Context, pipelineID string, schemaFile string, replace bool) (int, error) {
+	lock, _ := trainingLocks.LoadOrStore(pipelineID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	log.Infof("accumulating training data for pipeline '%s'", pipelineID)
+	_, span := tracing.Start(ctx, "accumulate training data")
+	defer span.End()
+
+	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFile, false)
+	if err != nil {
+		return 0, err
+	}
+	mainDR := meta.GetMainDataResource()
+	d3mIndex := -1
+	for _, v := range mainDR.Variables {
+		if v.Name == cm.D3MIndexName {
+			d3mIndex = v.Index
+		}
+	}
+	if d3mIndex < 0 {
+		return 0, errors.Errorf("d3m index not found in dataset")
+	}
+
+	datasetDir := path.Dir(schemaFile)
+	storeDir := env.ResolveTrainingPath(pipelineID)
+	if replace {
+		log.Infof("replacing training data stored in '%s'", storeDir)
+		err = os.RemoveAll(storeDir)
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable to remove training data for pipeline '%s'", pipelineID)
+		}
+	}
+
+	// merge the stored rows with the new ones
+	header := mainDR.GenerateHeader()
+	existing := make([][]string, 0)
+	storeDataPath := path.Join(storeDir, mainDR.ResPath)
+	if util.FileExists(storeDataPath) {
+		stored, err := util.ReadCSVFile(storeDataPath, false)
+		if err != nil {
+			return 0, err
+		}
+		if len(stored) > 0 {
+			existing, err = alignRows(stored[0], header, stored[1:])
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	incoming, err := ReadLearningData(schemaFile)
+	if err != nil {
+		return 0, err
+	}
+	merged, err := mergeRows(existing, incoming, d3mIndex)
+	if err != nil {
+		return 0, err
+	}
+	log.Infof("merged %d new rows with %d stored rows into %d rows", len(incoming), len(existing), len(merged))
+
+	// update the store, including any media referenced by the new rows
+	skip := map[string]bool{
+		compute.D3MDataSchema: true,
+		mainDR.ResPath:        true,
+	}
+	err = util.CopyFiles(datasetDir, storeDir, skip, true)
+	if err != nil {
+		return 0, err
+	}
+	err = util.WriteCSVFile(storeDataPath, header, merged)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to store training data for pipeline '%s'", pipelineID)
+	}
+
+	// make the dataset hold all the accumulated data
+	err = util.CopyFiles(storeDir, datasetDir, skip, false)
+	if err != nil {
+		return 0, err
+	}
+	err = util.WriteCSVFile(path.Join(datasetDir, mainDR.ResPath), header, merged)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to write accumulated training data")
+	}
+
+	return len(merged), nil
+}
+
+// alignRows reorders the columns of rows stored under the stored header to
+// match the current header. Columns missing from the stored rows are left
+// empty and columns no longer part of the dataset are dropped.
+func alignRows(stored []string, current []string, rows [][]string) ([][]string, error) {
+	positions := make(map[string]int)
+	for i, name := range stored {
+		positions[name] = i
+	}
+	if _, ok := positions[cm.D3MIndexName]; !ok {
+		return nil, util.NewInvalidError("stored training data has no '%s' column", cm.D3MIndexName)
+	}
+
+	columns := make([]int, len(current))
+	missing := make([]string, 0)
+	for i, name := range current {
+		position, ok := positions[name]
+		if !ok {
+			position = -1
+			missing = append(missing, name)
+		}
+		columns[i] = position
+	}
+	if len(missing) > 0 || len(stored) != len(current) {
+		log.Warnf("realigning stored training data to the dataset columns (missing %v)", missing)
+	}
+
+	aligned := make([][]string, len(rows))
+	for r, row := range rows {
+		aligned[r] = make([]string, len(current))
+		for i, position := range columns {
+			if position >= 0 && position < len(row) {
+				aligned[r][i] = row[position]
+			}
+		}
+	}
+
+	return aligned, nil
+}
+
+// mergeRows appends the incoming rows to the existing rows, replacing
+// existing rows that share the same index.
+func mergeRows(existing [][]string, incoming [][]string, indexColumn int) ([][]string, error) {
+	merged := make([][]string, 0, len(existing)+len(incoming))
+	positions := make(map[string]int)
+	for _, rows := range [][][]string{existing, incoming} {
+		for _, row := range rows {
+			if len(row) <= indexColumn || row[indexColumn] == "" {
+				return nil, util.NewInvalidError("rows need an id to be accumulated")
+			}
+			id := row[indexColumn]
+			if position, ok := positions[id]; ok {
+				merged[position] = row
+			} else {
+				positions[id] = len(merged)
+				merged = append(merged, row)
+			}
+		}
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,316 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-compute/metadata"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+const (
+	// ValidationNone skips validation.
+	ValidationNone = ""
+	// ValidationHoldout validates using a single train / holdout split.
+	ValidationHoldout = "holdout"
+	// ValidationKFold validates using k-fold cross validation.
+	ValidationKFold = "kfold"
+
+	// DefaultHoldoutRatio is the share of rows held out by default when
+	// validating using a single split.
+	DefaultHoldoutRatio = 0.2
+	// DefaultFolds is the default number of folds of k-fold cross validation.
+	DefaultFolds = 5
+)
+
+// ValidationOptions specifies how to validate a pipeline during fit.
+// Classification problems are always split in a stratified manner.
+type ValidationOptions struct {
+	Method       string
+	HoldoutRatio float64
+	Folds        int
+	Seed         int64
+}
+
+// FoldResult holds the scores of a single validation fold.
+type FoldResult struct {
+	Fold       int      `json:"fold"`
+	TrainCount int      `json:"trainCount"`
+	TestCount  int      `json:"testCount"`
+	Scores     []*Score `json:"scores"`
+}
+
+// ValidationResult holds the scores of every fold as well as the mean
+// score of every metric across the folds.
+type ValidationResult struct {
+	Method string        `json:"method"`
+	Folds  []*FoldResult `json:"folds"`
+	Scores []*Score      `json:"scores"`
+}
+
+// labelledDataset is a dataset with a known target that can be split to fit
+// and score pipelines.
+type labelledDataset struct {
+	pipelineID      string
+	predictionsID   string
+	schemaFile      string
+	problem         *Problem
+	meta            *model.Metadata
+	data            [][]string
+	targetIndex     int
+	d3mIndex        int
+	originalResPath string
+}
+
+func loadLabelledDataset(pipelineID string, schemaFile string, predictionsID string) (*labelledDataset, error) {
+	problem, err := LoadProblem(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := problem.GetTarget()
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFile, false)
+	if err != nil {
+		return nil, err
+	}
+	targetIndex, d3mIndex, err := getLabelIndices(meta, target)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ReadLearningData(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &labelledDataset{
+		pipelineID:      pipelineID,
+		predictionsID:   predictionsID,
+		schemaFile:      schemaFile,
+		problem:         problem,
+		meta:            meta,
+		data:            data,
+		targetIndex:     targetIndex,
+		d3mIndex:        d3mIndex,
+		originalResPath: meta.GetMainDataResource().ResPath,
+	}, nil
+}
+
+// fitRows fits the pipeline using a subset of the rows, writing the fitted
+// pipeline to the output path.
+func (l *labelledDataset) fitRows(ctx context.Context, batchID string, rows [][]string, outputPath string, config *env.Config) error {
+	log.Infof("fitting '%s' with %d rows", batchID, len(rows))
+	err := useBatch(ctx, l.meta, l.schemaFile, env.ResolveDatasetPath(l.predictionsID), batchID, rows)
+	if err != nil {
+		return err
+	}
+
+	_, err = fit(ctx, l.pipelineID, l.schemaFile, outputPath, config)
+	return err
+}
+
+// scoreRows scores the fitted pipeline on a subset of the rows, hiding their
+// labels from the pipeline.
+func (l *labelledDataset) scoreRows(ctx context.Context, batchID string, rows [][]string, fittedPath string, config *env.Config) (*ScoreResult, error) {
+	log.Infof("scoring '%s' with %d rows", batchID, len(rows))
+	unlabelled, labels, err := splitLabelRows(rows, l.targetIndex, l.d3mIndex)
+	if err != nil {
+		return nil, err
+	}
+	err = useBatch(ctx, l.meta, l.schemaFile, env.ResolveDatasetPath(l.predictionsID), batchID, unlabelled)
+	if err != nil {
+		return nil, err
+	}
+
+	produced, err := produce(ctx, l.pipelineID, fittedPath, l.schemaFile, l.predictionsID, config)
+	if err != nil {
+		return nil, err
+	}
+	predicted, err := GetPredictedValues(produced.Predictions())
+	if err != nil {
+		return nil, err
+	}
+
+	return ScorePredictions(l.problem, labels, predicted)
+}
+
+// restore points the schema back to the complete data.
+func (l *labelledDataset) restore() {
+	l.meta.GetMainDataResource().ResPath = l.originalResPath
+	err := metadata.WriteSchema(l.meta, l.schemaFile, false)
+	if err != nil {
+		log.Errorf("unable to restore dataset schema: %+v", err)
+	}
+}
+
+// Validate fits and scores the pipeline on splits of the dataset using the
+// metrics of the pipeline problem. The fitted pipeline is left untouched.
+func Validate(ctx context.Context, pipelineID string, schemaFile string, predictionsID string, options *ValidationOptions, config *env.Config) (*ValidationResult, error) {
+	log.Infof("validating pipeline '%s' using method '%s'", pipelineID, options.Method)
+	ctx, span := tracing.Start(ctx, "validate")
+	defer span.End()
+
+	labelled, err := loadLabelledDataset(pipelineID, schemaFile, predictionsID)
+	if err != nil {
+		return nil, err
+	}
+	defer labelled.restore()
+
+	folds, err := splitFolds(labelled.data, labelled.targetIndex, labelled.problem.IsClassification(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ValidationResult{
+		Method: options.Method,
+		Folds:  make([]*FoldResult, 0),
+	}
+	for i, testIndices := range folds {
+		trainData, testData := splitRows(labelled.data, testIndices)
+
+		// fit the fold to a temporary location and score the held out rows
+		fittedPath := path.Join(env.ResolvePredictionPath(predictionsID), fmt.Sprintf("fold-%d.d3m", i))
+		defer os.Remove(fittedPath)
+		err = labelled.fitRows(ctx, fmt.Sprintf("fold-%d-train", i), trainData, fittedPath, config)
+		if err != nil {
+			return nil, err
+		}
+		score, err := labelled.scoreRows(ctx, fmt.Sprintf("fold-%d-test", i), testData, fittedPath, config)
+		if err != nil {
+			return nil, err
+		}
+		result.Folds = append(result.Folds, &FoldResult{
+			Fold:       i,
+			TrainCount: len(trainData),
+			TestCount:  len(testData),
+			Scores:     score.Scores,
+		})
+	}
+	result.Scores = meanScores(result.Folds)
+	log.Infof("done validating pipeline '%s'", pipelineID)
+
+	return result, nil
+}
+
+// splitFolds returns the indices of the test rows of every fold.
+func splitFolds(data [][]string, targetIndex int, stratify bool, options *ValidationOptions) ([][]int, error) {
+	// group the rows by label when stratifying
+	groups := make(map[string][]int)
+	for i, row := range data {
+		key := ""
+		if stratify {
+			key = row[targetIndex]
+		}
+		groups[key] = append(groups[key], i)
+	}
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rng := rand.New(rand.NewSource(options.Seed))
+	switch options.Method {
+	case ValidationHoldout:
+		if options.HoldoutRatio <= 0 || options.HoldoutRatio >= 1 {
+			return nil, util.NewInvalidError("holdout ratio must be between 0 and 1")
+		}
+		test := make([]int, 0)
+		for _, k := range keys {
+			group := groups[k]
+			rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+			count := int(math.Round(options.HoldoutRatio * float64(len(group))))
+			test = append(test, group[:count]...)
+		}
+		if len(test) == 0 || len(test) == len(data) {
+			return nil, util.NewInvalidError("not enough rows to hold out %v of the data", options.HoldoutRatio)
+		}
+		return [][]int{test}, nil
+	case ValidationKFold:
+		if options.Folds < 2 || options.Folds > len(data) {
+			return nil, util.NewInvalidError("fold count must be between 2 and the number of rows")
+		}
+		// deal the rows to the folds, carrying on from group to group
+		folds := make([][]int, options.Folds)
+		count := 0
+		for _, k := range keys {
+			group := groups[k]
+			rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+			for _, i := range group {
+				folds[count%options.Folds] = append(folds[count%options.Folds], i)
+				count++
+			}
+		}
+		return folds, nil
+	default:
+		return nil, util.NewInvalidError("unsupported validation method '%s'", options.Method)
+	}
+}
+
+// splitRows separates the rows into training and test rows.
+func splitRows(data [][]string, testIndices []int) ([][]string, [][]string) {
+	isTest := make(map[int]bool)
+	for _, i := range testIndices {
+		isTest[i] = true
+	}
+
+	train := make([][]string, 0)
+	test := make([][]string, 0)
+	for i, row := range data {
+		if isTest[i] {
+			test = append(test, row)
+		} else {
+			train = append(train, row)
+		}
+	}
+	return train, test
+}
+
+func meanScores(folds []*FoldResult) []*Score {
+	totals := make(map[string]float64)
+	metrics := make([]string, 0)
+	for _, f := range folds {
+		for _, s := range f.Scores {
+			if _, ok := totals[s.Metric]; !ok {
+				metrics = append(metrics, s.Metric)
+			}
+			totals[s.Metric] = totals[s.Metric] + s.Value
+		}
+	}
+
+	scores := make([]*Score, len(metrics))
+	for i, m := range metrics {
+		scores[i] = &Score{
+			Metric: m,
+			Value:  totals[m] / float64(len(folds)),
+		}
+	}
+	return scores
+}
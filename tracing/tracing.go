@@ -0,0 +1,146 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"goji.io/v3/middleware"
+	"goji.io/v3/pat"
+	"goji.io/v3/pattern"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+)
+
+const (
+	tracerName = "github.com/uncharted-distil/distil-pipeline-executer"
+)
+
+var (
+	propagator = propagation.TraceContext{}
+	endpoint   = ""
+	insecure   = false
+)
+
+// Initialize sets up the export of spans to an OTLP collector. If tracing is
+// disabled, spans are still created but never exported. The returned
+// function flushes pending spans and should be called on shutdown.
+func Initialize(config *env.Config) (func(), error) {
+	otel.SetTextMapPropagator(propagator)
+	if !config.TracingEnabled {
+		return func() {}, nil
+	}
+
+	log.Infof("exporting traces to '%s'", config.TracingEndpoint)
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.TracingEndpoint)}
+	if config.TracingInsecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create trace exporter")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TracingSampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.TracingServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	endpoint = config.TracingEndpoint
+	insecure = config.TracingInsecure
+
+	return func() {
+		err := provider.Shutdown(context.Background())
+		if err != nil {
+			log.Errorf("unable to flush traces: %+v", err)
+		}
+	}, nil
+}
+
+// Start starts a span as a child of the span found in the context.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records the error, if any, on the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Environment returns the environment variables used to propagate the trace
+// context to a subprocess, along with the exporter settings so the
+// subprocess can export its own spans.
+func Environment(ctx context.Context) []string {
+	carrier := propagation.HeaderCarrier(http.Header{})
+	propagator.Inject(ctx, carrier)
+
+	variables := make([]string, 0)
+	if carrier.Get("traceparent") != "" {
+		variables = append(variables, "TRACEPARENT="+carrier.Get("traceparent"))
+	}
+	if carrier.Get("tracestate") != "" {
+		variables = append(variables, "TRACESTATE="+carrier.Get("tracestate"))
+	}
+	if endpoint != "" {
+		scheme := "https"
+		if insecure {
+			scheme = "http"
+		}
+		variables = append(variables, "OTEL_EXPORTER_OTLP_ENDPOINT="+scheme+"://"+endpoint)
+	}
+	return variables
+}
+
+// Middleware starts a span for every request, continuing any trace found
+// in the request headers. It must be used after routing.
+func Middleware(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if p, ok := middleware.Pattern(r.Context()).(*pat.Pattern); ok {
+			route = p.String()
+		}
+		pipelineID, _ := r.Context().Value(pattern.Variable("pipeline-id")).(string)
+
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := otel.Tracer(tracerName).Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("pipeline.id", pipelineID),
+			))
+		defer span.End()
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
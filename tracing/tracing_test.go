@@ -0,0 +1,225 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package tracing_test
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	log "github.com/unchartedsoftware/plog"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+
+	"github.com/uncharted-distil/distil-pipeline-executer/env"
+	"github.com/uncharted-distil/distil-pipeline-executer/internal/testrunner"
+	"github.com/uncharted-distil/distil-pipeline-executer/routes"
+	"github.com/uncharted-distil/distil-pipeline-executer/task"
+	"github.com/uncharted-distil/distil-pipeline-executer/tracing"
+	"github.com/uncharted-distil/distil-pipeline-executer/util"
+)
+
+func TestMain(m *testing.M) {
+	testrunner.RunIfRunner()
+	os.Exit(m.Run())
+}
+
+type receivedSpan struct {
+	name     string
+	traceID  string
+	spanID   string
+	parentID string
+}
+
+// receiver collects the spans exported over OTLP/HTTP.
+type receiver struct {
+	mutex sync.Mutex
+	spans map[string]*receivedSpan
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = gz
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request := &collectortrace.ExportTraceServiceRequest{}
+	err = proto.Unmarshal(data, request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rc.mutex.Lock()
+	for _, rs := range request.ResourceSpans {
+		for _, ils := range rs.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				rc.spans[s.Name] = &receivedSpan{
+					name:     s.Name,
+					traceID:  hex.EncodeToString(s.TraceId),
+					spanID:   hex.EncodeToString(s.SpanId),
+					parentID: hex.EncodeToString(s.ParentSpanId),
+				}
+			}
+		}
+	}
+	rc.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestExportSpans(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracing-test")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	rc := &receiver{spans: make(map[string]*receivedSpan)}
+	collector := httptest.NewServer(rc)
+	defer collector.Close()
+
+	traceParentFile := path.Join(dir, "traceparent")
+	variables := map[string]string{
+		"TRACING_ENABLED":  "true",
+		"TRACING_ENDPOINT": strings.TrimPrefix(collector.URL, "http://"),
+		"TRACING_INSECURE": "true",
+		"PIPELINE_DIR":     path.Join(dir, "pipelines"),
+		"DATASET_DIR":      path.Join(dir, "datasets"),
+		"PREDICTION_DIR":   path.Join(dir, "predictions"),
+		"RUN_DIR":          path.Join(dir, "runs"),
+		"JOB_DIR":          path.Join(dir, "jobs"),
+	}
+	for name, value := range variables {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	log.SetLevel(log.WarnLevel)
+	config, err := env.LoadConfig()
+	if err != nil {
+		t.Fatalf("unable to load config: %v", err)
+	}
+	err = testrunner.Configure(&config)
+	if err != nil {
+		t.Fatalf("unable to configure runner: %v", err)
+	}
+	env.Initialize(&config)
+	util.SetConfig(&config)
+	shutdown, err := tracing.Initialize(&config)
+	if err != nil {
+		t.Fatalf("unable to initialize tracing: %v", err)
+	}
+
+	// store a pipeline that looks fitted so produce calls the runner once
+	err = task.StorePipeline("test", []byte(testrunner.Pipeline), []byte(testrunner.DatasetSchema), []byte(testrunner.Problem), false)
+	if err != nil {
+		t.Fatalf("unable to store pipeline: %v", err)
+	}
+	err = ioutil.WriteFile(env.ResolvePipelineD3MPath("test"), []byte("fitted"), 0644)
+	if err != nil {
+		t.Fatalf("unable to store fitted pipeline: %v", err)
+	}
+
+	jobs, err := task.NewJobStore(config.JobDir)
+	if err != nil {
+		t.Fatalf("unable to create job store: %v", err)
+	}
+	server := httptest.NewServer(routes.NewMux(&config, routes.Routes(&config, task.NewQueue(0), jobs, "test", "test")))
+	defer server.Close()
+
+	// the runner only records the trace parent when asked to
+	os.Setenv(testrunner.TraceParentVariable, traceParentFile)
+	defer os.Unsetenv(testrunner.TraceParentVariable)
+	body := `{"rows": [{"id": "a", "data": {"x": "cat", "y": ""}}, {"id": "b", "data": {"x": "dog", "y": ""}}]}`
+	res, err := http.Post(server.URL+"/distil/produce/test", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("produce failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected produce to succeed, got status %d", res.StatusCode)
+	}
+
+	// flush the spans to the receiver
+	shutdown()
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	request := rc.spans["POST /distil/produce/:pipeline-id"]
+	dataset := rc.spans["create dataset"]
+	runnerSpan := rc.spans["runner produce"]
+	if request == nil || dataset == nil || runnerSpan == nil {
+		names := make([]string, 0, len(rc.spans))
+		for name := range rc.spans {
+			names = append(names, name)
+		}
+		t.Fatalf("expected request, dataset and runner spans, got %v", names)
+	}
+	if dataset.traceID != request.traceID || runnerSpan.traceID != request.traceID {
+		t.Errorf("expected the spans to share the trace of the request")
+	}
+	if !descends(rc.spans, dataset, request) || !descends(rc.spans, runnerSpan, request) {
+		t.Errorf("expected the dataset and runner spans to descend from the request span")
+	}
+
+	traceParent, err := ioutil.ReadFile(traceParentFile)
+	if err != nil {
+		t.Fatalf("runner did not record its trace parent: %v", err)
+	}
+	expected := fmt.Sprintf("00-%s-%s-01", runnerSpan.traceID, runnerSpan.spanID)
+	if string(traceParent) != expected {
+		t.Errorf("expected the runner to get TRACEPARENT '%s', got '%s'", expected, traceParent)
+	}
+}
+
+// descends returns true if the ancestor can be reached by following the
+// parents of the span.
+func descends(spans map[string]*receivedSpan, span *receivedSpan, ancestor *receivedSpan) bool {
+	byID := make(map[string]*receivedSpan)
+	for _, s := range spans {
+		byID[s.spanID] = s
+	}
+	for current := span; current != nil; current = byID[current.parentID] {
+		if current.parentID == ancestor.spanID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+//
+//   Copyright © 2020 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package util
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrorKind classifies errors so they can be reported properly to clients.
+type ErrorKind int
+
+const (
+	// KindInternal is an unexpected failure on the server.
+	KindInternal ErrorKind = iota
+	// KindBadRequest is a malformed request or payload.
+	KindBadRequest
+	// KindNotFound is a reference to something that does not exist.
+	KindNotFound
+	// KindConflict is a request that conflicts with the current state.
+	KindConflict
+	// KindInvalid is a well formed request that fails validation.
+	KindInvalid
+	// KindUnavailable is a request that cannot be served at the moment.
+	KindUnavailable
+	// KindUnauthorized is a request without valid credentials.
+	KindUnauthorized
+	// KindForbidden is a request whose credentials do not grant access.
+	KindForbidden
+	// KindRateLimited is a request exceeding the rate allowed to its caller.
+	KindRateLimited
+)
+
+type kindError struct {
+	kind    ErrorKind
+	details interface{}
+	err     error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Cause returns the underlying error.
+func (e *kindError) Cause() error {
+	return e.err
+}
+
+// WithKind tags the error with the specified kind.
+func WithKind(err error, kind ErrorKind) error {
+	return WithKindDetails(err, kind, nil)
+}
+
+// WithKindDetails tags the error with the specified kind and details to
+// report to the client.
+func WithKindDetails(err error, kind ErrorKind, details interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{
+		kind:    kind,
+		details: details,
+		err:     err,
+	}
+}
+
+// GetErrorKind returns the kind of the error, looking through wrapped errors.
+// Untagged errors are internal errors.
+func GetErrorKind(err error) (ErrorKind, interface{}) {
+	for err != nil {
+		if ke, ok := err.(*kindError); ok {
+			return ke.kind, ke.details
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+	return KindInternal, nil
+}
+
+// NewBadRequestError returns an error for a malformed request.
+func NewBadRequestError(format string, args ...interface{}) error {
+	return WithKind(errors.Errorf(format, args...), KindBadRequest)
+}
+
+// NewNotFoundError returns an error for a missing resource.
+func NewNotFoundError(format string, args ...interface{}) error {
+	return WithKind(errors.Errorf(format, args...), KindNotFound)
+}
+
+// NewInvalidError returns an error for a request failing validation.
+func NewInvalidError(format string, args ...interface{}) error {
+	return WithKind(errors.Errorf(format, args...), KindInvalid)
+}
+
+// NewUnauthorizedError returns an error for a request without valid credentials.
+func NewUnauthorizedError(format string, args ...interface{}) error {
+	return WithKind(errors.Errorf(format, args...), KindUnauthorized)
+}
+
+// NewForbiddenError returns an error for a request that is not allowed.
+func NewForbiddenError(format string, args ...interface{}) error {
+	return WithKind(errors.Errorf(format, args...), KindForbidden)
+}
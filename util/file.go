@@ -16,6 +16,7 @@
 package util
 
 import (
+	"bytes"
 	"encoding/csv"
 	"io"
 	"io/ioutil"
@@ -115,8 +116,9 @@ func FileExists(filename string) bool {
 	return true
 }
 
-// RemoveContents removes the files and directories from the supplied parent.
-func RemoveContents(dir string) error {
+// RemoveContents removes the files and directories from the supplied parent,
+// except for those named in keep.
+func RemoveContents(dir string, keep ...string) error {
 	d, err := os.Open(dir)
 	if err != nil {
 		return errors.Wrap(err, "unable to open directory")
@@ -126,7 +128,14 @@ func RemoveContents(dir string) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to read directory contents")
 	}
+	kept := make(map[string]bool)
+	for _, name := range keep {
+		kept[name] = true
+	}
 	for _, name := range names {
+		if kept[name] {
+			continue
+		}
 		err = os.RemoveAll(filepath.Join(dir, name))
 		if err != nil {
 			return errors.Wrap(err, "unable to remove file from directory")
@@ -170,3 +179,56 @@ func ReadCSVFile(filename string, hasHeader bool) ([][]string, error) {
 
 	return lines, nil
 }
+
+// WriteCSVFile writes the header and data to a csv file, creating any missing
+// directories along the way.
+func WriteCSVFile(filename string, header []string, data [][]string) error {
+	outputBytes := &bytes.Buffer{}
+	writerOutput := csv.NewWriter(outputBytes)
+	if header != nil {
+		err := writerOutput.Write(header)
+		if err != nil {
+			return errors.Wrap(err, "unable to write header")
+		}
+	}
+	err := writerOutput.WriteAll(data)
+	if err != nil {
+		return errors.Wrap(err, "unable to write data")
+	}
+	writerOutput.Flush()
+
+	return WriteFileWithDirs(filename, outputBytes.Bytes(), os.ModePerm)
+}
+
+// CopyFiles copies the files found under the source directory to the
+// destination directory, preserving the relative paths. Files listed in skip
+// (relative to the source) are not copied, and existing files are only
+// replaced if overwrite is set.
+func CopyFiles(sourceDir string, destinationDir string, skip map[string]bool, overwrite bool) error {
+	return filepath.Walk(sourceDir, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrapf(err, "unable to read '%s'", filename)
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(sourceDir, filename)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get relative path of '%s'", filename)
+		}
+		if skip[relativePath] {
+			return nil
+		}
+		destination := filepath.Join(destinationDir, relativePath)
+		if !overwrite && FileExists(destination) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read '%s'", filename)
+		}
+		return WriteFileWithDirs(destination, data, info.Mode())
+	})
+}